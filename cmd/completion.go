@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script that completes commands, workflow keys,
+params and --set values.
+
+  bash:        source <(cmdr completion bash)
+  zsh:         cmdr completion zsh > "${fpath[1]}/_cmdr"
+  fish:        cmdr completion fish > ~/.config/fish/completions/cmdr.fish
+  powershell:  cmdr completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		return fmt.Errorf("unsupported shell %q", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+// completeWorkflowKeys offers every stored workflow key, described by the
+// workflow's description or name.
+func completeWorkflowKeys(toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := workflow.NewStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	workflows, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, wf := range workflows {
+		if !strings.HasPrefix(wf.Key, toComplete) {
+			continue
+		}
+		description := wf.Description
+		if description == "" {
+			description = wf.Name
+		}
+		completions = append(completions, wf.Key+"\t"+description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRunArgs completes `cmdr run`. Flags after the workflow key aren't
+// parsed by cobra, so the workflow's flags are completed here by hand.
+func completeRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeWorkflowKeys(toComplete)
+	}
+
+	store, err := workflow.NewStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	wf, err := store.LoadResolved(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	fs := wf.FlagSet()
+	rest := args[1:]
+
+	// The value of a flag, either "--flag value" or "--flag=value".
+	if name, value, found := strings.Cut(toComplete, "="); found && strings.HasPrefix(name, "--") {
+		completions, directive := completeFlagValue(wf, strings.TrimPrefix(name, "--"), value)
+		for i := range completions {
+			completions[i] = name + "=" + completions[i]
+		}
+		return completions, directive
+	}
+	if len(rest) > 0 {
+		if flag := lookupFlag(fs, rest[len(rest)-1]); flag != nil && flag.NoOptDefVal == "" {
+			return completeFlagValue(wf, flag.Name, toComplete)
+		}
+	}
+
+	if strings.HasPrefix(toComplete, "-") {
+		var completions []string
+		fs.VisitAll(func(flag *pflag.Flag) {
+			completions = append(completions, "--"+flag.Name+"\t"+flag.Usage)
+		})
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Count the positional arguments given so far, skipping flag values.
+	n := 0
+	for i := 0; i < len(rest); i++ {
+		if !strings.HasPrefix(rest[i], "-") {
+			n++
+			continue
+		}
+		if flag := lookupFlag(fs, rest[i]); flag != nil && flag.NoOptDefVal == "" && !strings.Contains(rest[i], "=") {
+			i++
+		}
+	}
+	p, ok := wf.PositionalAt(n)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return optionCompletions(wf, p.Name), cobra.ShellCompDirectiveNoFileComp
+}
+
+// lookupFlag finds the flag named by an argument such as "--tag" or "-t".
+func lookupFlag(fs *pflag.FlagSet, arg string) *pflag.Flag {
+	arg, _, _ = strings.Cut(arg, "=")
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		return fs.Lookup(arg[2:])
+	case strings.HasPrefix(arg, "-") && len(arg) == 2:
+		return fs.ShorthandLookup(arg[1:])
+	}
+	return nil
+}
+
+// completeFlagValue completes the value of one of a workflow's flags.
+func completeFlagValue(wf *workflow.Workflow, name, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch name {
+	case "set":
+		return completeSet(wf, toComplete)
+	case "export":
+		return workflow.ExportShells, cobra.ShellCompDirectiveNoFileComp
+	}
+	return optionCompletions(wf, name), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSet completes --set: first the variables the workflow asks for,
+// then, once "name=" is typed, the options of that variable's select step.
+func completeSet(wf *workflow.Workflow, toComplete string) ([]string, cobra.ShellCompDirective) {
+	name, _, found := strings.Cut(toComplete, "=")
+	if !found {
+		var completions []string
+		for _, step := range wf.Steps {
+			if step.Variable != "" {
+				completions = append(completions, step.Variable+"=\t"+step.Prompt)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, value := range optionCompletions(wf, name) {
+		completions = append(completions, name+"="+value)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
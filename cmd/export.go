@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// Values for export --format.
+const (
+	formatBundle = "bundle"
+	formatShell  = "sh"
+)
+
+var (
+	exportFormat string
+	exportOut    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <workflow-name...> [-o file]",
+	Short: "Export workflows as a bundle to share, or as a standalone shell script",
+	Long: `Write workflows to a bundle, a YAML file that 'cmdr import' reads on another
+machine, so a project's workflows can be handed to teammates. The fragments
+they use are included. Bundles written to a .json file are JSON:
+
+  cmdr export build deploy -o bundle.yaml
+  cmdr export build deploy | ssh host cmdr import -
+
+With --format sh, print a bash script that runs a single workflow without
+cmdr, for machines that don't have it installed. Params become arguments
+and flags, prompts become read and select, and conditions become if blocks.
+Anything the script can't reproduce is listed as a warning in its header and
+on stderr.
+
+  cmdr export deploy --format sh > deploy.sh`,
+	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		keys, directive := completeWorkflowKeys(toComplete)
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			return slices.Contains(args, key)
+		})
+		return keys, directive
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch exportFormat {
+		case formatBundle:
+		case formatShell:
+			if len(args) > 1 {
+				return fmt.Errorf("--format %s exports one workflow at a time", formatShell)
+			}
+		default:
+			return fmt.Errorf("unknown format %q (want %s or %s)", exportFormat, formatBundle, formatShell)
+		}
+
+		store, err := workflow.NewStore()
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if exportOut != "" && exportOut != "-" {
+			mode := os.FileMode(0644)
+			if exportFormat == formatShell {
+				mode = 0755
+			}
+			f, err := os.OpenFile(exportOut, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if exportFormat == formatShell {
+			wf, err := store.LoadResolved(args[0])
+			if err != nil {
+				return err
+			}
+			warnings, err := wf.WriteShellScript(out)
+			if err != nil {
+				return err
+			}
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+			}
+			return nil
+		}
+
+		bundle, err := store.Bundle(args)
+		if err != nil {
+			return err
+		}
+		for _, key := range bundle.MissingBases() {
+			fmt.Fprintf(os.Stderr, "warning: %s is extended by a workflow in the bundle but isn't in it\n", key)
+		}
+		if err := workflow.WriteBundle(out, bundle, workflow.FormatOf(exportOut)); err != nil {
+			return err
+		}
+		if out != os.Stdout {
+			fmt.Fprintf(os.Stderr, "✅ Exported %d workflow(s) to %s\n", len(bundle.Workflows), exportOut)
+		}
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", formatBundle, "output format: bundle or sh")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "write to this file instead of stdout")
+	exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{formatBundle, formatShell}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(exportCmd)
+}
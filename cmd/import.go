@@ -0,0 +1,466 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/importer"
+	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/kevmul/cmdr/internal/ui"
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	importFrom       string
+	importPrefix     string
+	importAll        bool
+	importOnly       []string
+	importDryRun     bool
+	importReplace    bool
+	importOnConflict string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [bundle.yaml | - | --from make|npm|just|taskfile [path]]",
+	Short: "Import a workflow bundle, or create workflows from Makefile targets, npm scripts, just recipes or Taskfile tasks",
+	Long: `Without --from, import the workflows in a bundle written by 'cmdr export',
+or any YAML or JSON file listing workflows. The bundle is read from the file
+given, or from stdin if that's "-" or stdin is a pipe:
+
+  cmdr import bundle.yaml
+  curl -s https://example.com/team.yaml | cmdr import
+
+With --from, read the targets, scripts or recipes of a task file and create a workflow for
+each, which runs it from the file's directory. Descriptions come from "## "
+comments (make), the comment above a recipe (just), "scripts-info" (npm) or
+desc fields (taskfile), and dependencies are noted in each description.
+
+path is the task file or the directory holding it, the current directory by
+default. Pick which workflows to save from a preview, or choose them with
+--all or --only.
+
+When an imported workflow's key is taken by a different workflow, the two
+are diffed and you're asked whether to skip it, overwrite the existing one or
+rename it with a suffix (deploy-2). --on-conflict decides for all of them,
+and when there's no terminal to ask on, they're skipped.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if importFrom == "" {
+			return importBundle(args)
+		}
+
+		src, err := importer.Lookup(importFrom)
+		if err != nil {
+			return err
+		}
+
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		file, tasks, err := src.Load(path)
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			fmt.Printf("No tasks found in %s.\n", file)
+			return nil
+		}
+
+		prefix := importPrefix
+		if !cmd.Flags().Changed("prefix") {
+			dir, err := filepath.Abs(filepath.Dir(file))
+			if err != nil {
+				return err
+			}
+			prefix = filepath.Base(dir)
+		}
+		workflows, err := src.Workflows(file, tasks, prefix)
+		if err != nil {
+			return err
+		}
+		if len(importOnly) > 0 {
+			if workflows, err = onlyTasks(workflows, tasks, importOnly); err != nil {
+				return err
+			}
+		}
+
+		if importDryRun {
+			data, err := yaml.Marshal(workflows)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(data))
+			return nil
+		}
+
+		action, err := conflictAction()
+		if err != nil {
+			return err
+		}
+		store, err := workflow.NewStore()
+		if err != nil {
+			return err
+		}
+		notes := make([]string, len(workflows))
+		for i, wf := range workflows {
+			if store.KeyExists(wf.Key) {
+				switch action {
+				case workflow.ConflictOverwrite:
+					notes[i] = "replaces existing"
+				case workflow.ConflictRename:
+					notes[i] = "key exists, will be renamed"
+				default:
+					notes[i] = "key exists"
+				}
+			}
+		}
+
+		var picked []workflow.Workflow
+		switch {
+		case importAll || len(importOnly) > 0:
+			picked = workflows
+		case !isTerminal(os.Stdin) || !isTerminal(os.Stdout):
+			return errors.New("not a terminal: choose tasks with --all or --only, or preview them with --dry-run")
+		default:
+			title := fmt.Sprintf("Import from %s", file)
+			if picked, err = ui.RunPicker(title, workflows, notes); err != nil {
+				return err
+			}
+			if picked == nil {
+				fmt.Println("Cancelled.")
+				return nil
+			}
+		}
+
+		saved, err := saveImported(store, picked, action)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Imported %d workflow(s) from %s\n", saved, file)
+		return nil
+	},
+}
+
+// importBundle imports the workflows in the bundle named by args, or the
+// one on stdin.
+func importBundle(args []string) error {
+	name := "-"
+	if len(args) == 1 {
+		name = args[0]
+	} else if isTerminal(os.Stdin) {
+		return errors.New("give a bundle file to import, \"-\" to read one from stdin, or --from to import a task file")
+	}
+
+	var in io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	} else {
+		name = "stdin"
+	}
+
+	bundle, err := workflow.ReadBundle(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	workflows := bundle.Workflows
+	if len(importOnly) > 0 {
+		if workflows, err = onlyKeys(workflows, importOnly); err != nil {
+			return err
+		}
+	}
+	if len(workflows) == 0 {
+		fmt.Printf("No workflows found in %s.\n", name)
+		return nil
+	}
+
+	action, err := conflictAction()
+	if err != nil {
+		return err
+	}
+	store, err := workflow.NewStore()
+	if err != nil {
+		return err
+	}
+
+	if importDryRun {
+		return previewImport(store, workflows)
+	}
+
+	if err := saveFragments(store, bundle.Fragments, action); err != nil {
+		return err
+	}
+	saved, err := saveImported(store, workflows, action)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Imported %d workflow(s) from %s\n", saved, name)
+	return nil
+}
+
+// saveFragments adds a bundle's fragments to the library. Existing ones
+// that differ are only replaced with --on-conflict overwrite, since other
+// workflows may depend on them.
+func saveFragments(store *workflow.Store, fragments map[string]workflow.Fragment, action workflow.ConflictAction) error {
+	if len(fragments) == 0 {
+		return nil
+	}
+	library, err := store.Fragments()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, name := range slices.Sorted(maps.Keys(fragments)) {
+		fragment := fragments[name]
+		verb := "Added"
+		if existing, ok := library[name]; ok {
+			if reflect.DeepEqual(existing, fragment) {
+				continue
+			}
+			if action != workflow.ConflictOverwrite {
+				fmt.Printf("Kept fragment %s: it differs from the bundle's (use --on-conflict overwrite to replace it)\n", name)
+				continue
+			}
+			verb = "Replaced"
+		}
+		library[name] = fragment
+		changed = true
+		fmt.Printf("%s fragment %s\n", verb, name)
+	}
+	if !changed {
+		return nil
+	}
+	return store.SaveFragments(library)
+}
+
+// conflictAction returns the action chosen with --on-conflict or --replace,
+// or "" to ask about each conflict.
+func conflictAction() (workflow.ConflictAction, error) {
+	if importOnConflict != "" {
+		if importReplace {
+			return "", errors.New("--replace and --on-conflict can't be used together")
+		}
+		return workflow.ParseConflictAction(importOnConflict)
+	}
+	if importReplace {
+		return workflow.ConflictOverwrite, nil
+	}
+	return "", nil
+}
+
+// previewImport prints what importing workflows would do, with a diff for
+// each one that would change an existing workflow.
+func previewImport(store *workflow.Store, workflows []workflow.Workflow) error {
+	for _, wf := range workflows {
+		existing, err := store.Load(wf.Key)
+		if err != nil {
+			fmt.Printf("%s %s %s\n", styles.SuccessStyle.Render("+"), wf.Key, styles.MutedTextStyle.Render("(new)"))
+			continue
+		}
+		diff, err := workflow.DiffWorkflows(existing, &wf)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			fmt.Printf("= %s %s\n", wf.Key, styles.MutedTextStyle.Render("(unchanged)"))
+			continue
+		}
+		fmt.Printf("%s %s %s\n", styles.ErrorStyle.Render("~"), wf.Key, styles.MutedTextStyle.Render("(key exists)"))
+		printDiff(diff)
+	}
+	return nil
+}
+
+// saveImported saves workflows, resolving each key conflict with action,
+// or by asking on the terminal if action is empty. Workflows identical to
+// the one they'd replace are left alone. It returns how many were saved.
+func saveImported(store *workflow.Store, workflows []workflow.Workflow, action workflow.ConflictAction) (int, error) {
+	incoming := make(map[string]bool, len(workflows))
+	for _, wf := range workflows {
+		incoming[wf.Key] = true
+	}
+
+	var tty *bufio.Reader
+	if action == "" {
+		if f, err := os.Open("/dev/tty"); err == nil && isTerminal(os.Stdout) {
+			defer f.Close()
+			tty = bufio.NewReader(f)
+		} else {
+			action = workflow.ConflictSkip
+		}
+	}
+
+	saved := 0
+	for _, wf := range workflows {
+		existing, err := store.Load(wf.Key)
+		if err == nil {
+			diff, err := workflow.DiffWorkflows(existing, &wf)
+			if err != nil {
+				return saved, err
+			}
+			if diff == "" {
+				fmt.Printf("Unchanged %s (%s)\n", wf.Name, wf.Key)
+				continue
+			}
+
+			chosen := action
+			if chosen == "" {
+				fmt.Printf("\nWorkflow %q already exists and differs:\n", wf.Key)
+				printDiff(diff)
+				if chosen, action, err = askConflict(tty); err != nil {
+					return saved, err
+				}
+			}
+
+			switch chosen {
+			case workflow.ConflictSkip:
+				fmt.Printf("Skipped %s: key %q is taken\n", wf.Name, wf.Key)
+				continue
+			case workflow.ConflictRename:
+				key, err := store.FreeKey(wf.Key, incoming)
+				if err != nil {
+					return saved, err
+				}
+				fmt.Printf("Renamed %s: %s → %s\n", wf.Name, wf.Key, key)
+				incoming[key] = true
+				wf.Key = key
+			}
+		}
+
+		if err := store.Save(&wf); err != nil {
+			return saved, err
+		}
+		saved++
+	}
+	return saved, nil
+}
+
+// askConflict asks what to do about one conflicting workflow. A capital
+// letter applies the answer to the remaining conflicts too, which it
+// returns as the new default.
+func askConflict(tty *bufio.Reader) (chosen, rest workflow.ConflictAction, err error) {
+	for {
+		fmt.Print("[s]kip, [o]verwrite or [r]ename? (capital for all remaining) ")
+		line, err := tty.ReadString('\n')
+		if err != nil && line == "" {
+			return "", "", fmt.Errorf("reading answer: %w", err)
+		}
+		answer := strings.TrimSpace(line)
+		all := answer != strings.ToLower(answer)
+		switch strings.ToLower(answer) {
+		case "s", "skip":
+			chosen = workflow.ConflictSkip
+		case "o", "overwrite":
+			chosen = workflow.ConflictOverwrite
+		case "r", "rename":
+			chosen = workflow.ConflictRename
+		default:
+			continue
+		}
+		if all {
+			return chosen, chosen, nil
+		}
+		return chosen, "", nil
+	}
+}
+
+// printDiff prints a diff from workflow.DiffWorkflows, indented and in
+// colour.
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "-"):
+			line = styles.ErrorStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = styles.SuccessStyle.Render(line)
+		default:
+			line = styles.MutedTextStyle.Render(line)
+		}
+		fmt.Println("    " + line)
+	}
+}
+
+// onlyTasks keeps the workflows for the named tasks, in the order given.
+func onlyTasks(workflows []workflow.Workflow, tasks []importer.Task, names []string) ([]workflow.Workflow, error) {
+	byName := make(map[string]workflow.Workflow, len(tasks))
+	for i, task := range tasks {
+		byName[task.Name] = workflows[i]
+	}
+
+	var kept []workflow.Workflow
+	var missing []string
+	for _, name := range names {
+		wf, ok := byName[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		kept = append(kept, wf)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no such task: %s", strings.Join(missing, ", "))
+	}
+	return kept, nil
+}
+
+// onlyKeys keeps the workflows with the given keys, in the order given.
+func onlyKeys(workflows []workflow.Workflow, keys []string) ([]workflow.Workflow, error) {
+	byKey := make(map[string]workflow.Workflow, len(workflows))
+	for _, wf := range workflows {
+		byKey[wf.Key] = wf
+	}
+
+	var kept []workflow.Workflow
+	var missing []string
+	for _, key := range keys {
+		wf, ok := byKey[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		kept = append(kept, wf)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no such workflow in bundle: %s", strings.Join(missing, ", "))
+	}
+	return kept, nil
+}
+
+func init() {
+	var sources []string
+	for _, src := range importer.Sources {
+		sources = append(sources, src.Name)
+	}
+
+	var actions []string
+	for _, action := range workflow.ConflictActions {
+		actions = append(actions, string(action))
+	}
+
+	importCmd.Flags().StringVar(&importFrom, "from", "", "kind of task file: "+strings.Join(sources, ", ")+" (default: a bundle)")
+	importCmd.Flags().StringVar(&importPrefix, "prefix", "", "prefix for workflow names and keys (default: the project's directory name)")
+	importCmd.Flags().BoolVar(&importAll, "all", false, "import every task without asking")
+	importCmd.Flags().StringSliceVar(&importOnly, "only", nil, "import only these tasks, or these workflows of a bundle, without asking")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "show what would be imported instead of saving it")
+	importCmd.Flags().BoolVar(&importReplace, "replace", false, "overwrite workflows whose key is already taken (same as --on-conflict overwrite)")
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", "", "what to do when a key is taken: "+strings.Join(actions, ", ")+" (default: ask)")
+	importCmd.RegisterFlagCompletionFunc("on-conflict", cobra.FixedCompletions(actions, cobra.ShellCompDirectiveNoFileComp))
+	importCmd.RegisterFlagCompletionFunc("from", cobra.FixedCompletions(sources, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(importCmd)
+}
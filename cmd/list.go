@@ -2,15 +2,12 @@ package cmd
 
 import (
 	"fmt"
-	// "strings"
 
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 
-	// "github.com/charmbracelet/lipgloss"
-	// "github.com/kevmul/cmdr/internal/styles"
-	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kevmul/cmdr/internal/ui"
 	"github.com/kevmul/cmdr/internal/workflow"
 	"github.com/spf13/cobra"
 )
@@ -18,29 +15,18 @@ import (
 // ─── Inline workflow selector model ───────────────────────────────────────────
 
 type workflowSelectModel struct {
-	workflows []workflow.Workflow
-	selected  *workflow.Workflow
-	list      list.Model
-	listItems []list.Item
-	done      bool
+	selected *workflow.Workflow
+	list     ui.WorkflowList
+	width    int
+	done     bool
 }
 
 type keyMap struct {
-	Up   key.Binding
-	Down key.Binding
 	Run  key.Binding
 	Quit key.Binding
 }
 
 var keys = keyMap{
-	Up: key.NewBinding(
-		key.WithKeys("k", "up"),
-		key.WithHelp("k, up", "Move up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("j", "down"),
-		key.WithHelp("j, down", "Move down"),
-	),
 	Run: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("↵", "run"),
@@ -53,66 +39,43 @@ var keys = keyMap{
 
 func (m workflowSelectModel) Init() tea.Cmd { return nil }
 
-type workflowItem struct {
-	workflow.Workflow
-}
-
-func (i workflowItem) Title() string {
-	key := styles.MutedTextStyle.Render(fmt.Sprintf("(%s)", i.Key))
-	return fmt.Sprintf("%s %s", i.Name, key)
-}
-func (i workflowItem) Description() string { return i.Workflow.Description }
-func (i workflowItem) FilterValue() string { return i.Name }
-
-func NewWorkflowSelectModel(workflows []workflow.Workflow) workflowSelectModel {
-
-	items := make([]list.Item, len(workflows))
-	for i, item := range workflows {
-		items[i] = workflowItem{item}
+func NewWorkflowSelectModel(store *workflow.Store) (workflowSelectModel, error) {
+	l, err := ui.NewWorkflowList(store)
+	if err != nil {
+		return workflowSelectModel{}, err
 	}
-
-	d := list.NewDefaultDelegate()
-	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(styles.Primary).BorderLeftForeground(styles.Primary)
-	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(styles.Tertiary).BorderLeftForeground(styles.Primary)
-
-	l := list.New(items, d, 0, 0)
-	l.Title = "Command Runner"
-	l.SetShowTitle(false)
-	l.SetShowStatusBar(true)
-	l.SetFilteringEnabled(true)
-	l.SetShowHelp(false)
-	l.SetShowHelp(false)
 	return workflowSelectModel{
-		workflows: workflows,
-		listItems: items,
-		list:      l,
-		selected:  nil,
-		done:      false,
-	}
+		list:     l,
+		selected: nil,
+		done:     false,
+	}, nil
 }
 
 func (m workflowSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		const itemHeight = 2
 		const maxVisibleItems = 10
-		h := min(len(m.listItems)*itemHeight+4, maxVisibleItems*itemHeight+4)
-		m.list.SetSize(msg.Width, h)
+		h := min(m.list.Rows()*itemHeight+4, maxVisibleItems*itemHeight+4)
+		m.width = msg.Width
+		m.list.SetSize(msg.Width-ui.PreviewWidth(msg.Width), h)
 	case tea.KeyMsg:
+		if m.list.Filtering() {
+			break
+		}
 		switch {
 		case key.Matches(msg, keys.Run):
-			m.selected = &m.workflows[m.list.Index()]
-			m.done = true
-			return m, tea.Quit
+			if wf := m.list.Selected(); wf != nil {
+				m.selected = wf
+				m.done = true
+				return m, tea.Quit
+			}
 		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
 		}
 	}
-	m.list, cmd = m.list.Update(msg)
 
-	return m, cmd
+	return m, m.list.Update(msg)
 }
 
 func (m workflowSelectModel) View() string {
@@ -122,7 +85,17 @@ func (m workflowSelectModel) View() string {
 		return ""
 	}
 
-	return m.list.View()
+	width := ui.PreviewWidth(m.width)
+	if width == 0 {
+		return m.list.View()
+	}
+
+	// The inline list is only as tall as its items, so give the preview a
+	// little more room when a workflow has several steps.
+	const minPreviewHeight = 12
+	preview := ui.RenderPreview(m.list.Selected(), width, max(m.list.Height(), minPreviewHeight))
+	listView := lipgloss.NewStyle().Width(m.list.Width()).Render(m.list.View())
+	return lipgloss.JoinHorizontal(lipgloss.Top, listView, preview)
 }
 
 // ─── Command ──────────────────────────────────────────────────────────────────
@@ -137,17 +110,16 @@ var listCmd = &cobra.Command{
 			return err
 		}
 
-		workflows, err := store.List()
+		m, err := NewWorkflowSelectModel(store)
 		if err != nil {
 			return err
 		}
 
-		if len(workflows) == 0 {
+		if m.list.Len() == 0 {
 			fmt.Println("No workflows found. Run 'cmdr' to create one!")
 			return nil
 		}
 
-		m := NewWorkflowSelectModel(workflows)
 		p := tea.NewProgram(m)
 
 		result, err := p.Run()
@@ -161,8 +133,7 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
-		executor := workflow.NewExecutor()
-		return executor.Execute(final.selected)
+		return runSelected(cmd.Context(), store, final.selected)
 	},
 }
 
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kevmul/cmdr/internal/ui"
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create a workflow step by step",
+	Long:  "Walk through naming a workflow and building its steps in an interactive form, with a live YAML preview",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := workflow.NewStore()
+		if err != nil {
+			return err
+		}
+
+		wf, err := ui.RunBuilder(store)
+		if err != nil {
+			return err
+		}
+		if wf == nil {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+
+		fmt.Printf("✅ Created workflow %s. Run it with: cmdr run %s\n", wf.Name, wf.Key)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+}
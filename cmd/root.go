@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kevmul/cmdr/internal/ui"
 	"github.com/kevmul/cmdr/internal/workflow"
 	"github.com/spf13/cobra"
@@ -20,19 +21,38 @@ Store your common dev tasks as reusable workflows with inputs, selections, and c
 			return err
 		}
 
-		m, err := ui.NewMainModel(store)
-		p := tea.NewProgram(m)
-		_, err = p.Run()
+		selected, err := ui.RunMainUI(store)
 		if err != nil {
 			return err
 		}
+		if selected == nil {
+			return nil
+		}
 
-		return nil
+		return runSelected(cmd.Context(), store, selected)
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "print workflow output inline instead of using the full-screen run view")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "output format: text, or json for a stream of run events")
+	rootCmd.PersistentFlags().DurationVar(&gracePeriod, "grace-period", workflow.DefaultGracePeriod, "how long an interrupted command has to exit before it is killed")
+	rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{outputText, outputJSON}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// Execute runs the CLI. A workflow stopped by ctrl+c or SIGTERM exits with
+// 128 plus the signal number, like a shell.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := workflow.NotifyContext(context.Background())
+	defer stop()
+
+	addWorkflowCommands(rootCmd)
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		var intr *workflow.Interrupted
+		if errors.As(err, &intr) {
+			os.Exit(intr.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
@@ -1,16 +1,127 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"time"
+
 	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/mattn/go-isatty"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	plainOutput  bool
+	gracePeriod  time.Duration
+	outputFormat string
+)
+
+// Values for --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
 )
 
+// newExecutor creates an executor that writes to out, using the
+// full-screen run view when out is a terminal unless --plain was passed.
+// With --output json the run is shown inline on stderr and its events are
+// written to stdout instead.
+func newExecutor(out *os.File) (*workflow.Executor, error) {
+	switch outputFormat {
+	case outputText:
+		return workflow.NewExecutor(
+			workflow.WithStdout(out),
+			workflow.WithRunView(!plainOutput && isTerminal(out)),
+			workflow.WithGracePeriod(gracePeriod),
+		), nil
+	case outputJSON:
+		return workflow.NewExecutor(
+			workflow.WithStdout(os.Stderr),
+			workflow.WithObserver(workflow.NewJSONObserver(os.Stdout)),
+			workflow.WithGracePeriod(gracePeriod),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want %s or %s)", outputFormat, outputText, outputJSON)
+	}
+}
+
+// Set by the shell-init wrapper: after a successful run the captured env
+// is written to the file, in the given shell's syntax, for the wrapper to
+// source.
+const (
+	exportFileEnv  = "CMDR_EXPORT_FILE"
+	exportShellEnv = "CMDR_EXPORT_SHELL"
+)
+
+// runWorkflow records the run in the user's history and executes it with
+// vars preset. When export names a shell, the captured env is printed to
+// stdout in its syntax and the run itself is shown on stderr.
+func runWorkflow(ctx context.Context, store *workflow.Store, wf *workflow.Workflow, vars map[string]string, export string) error {
+	if err := store.RecordRun(wf.Key); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not record run history: %v\n", err)
+	}
+
+	out := os.Stdout
+	if export != "" {
+		if outputFormat == outputJSON {
+			return errors.New("--export can't be combined with --output json")
+		}
+		// Keep stdout clean for eval; everything the run prints, including
+		// the run view, goes to stderr.
+		out = os.Stderr
+	}
+
+	executor, err := newExecutor(out)
+	if err != nil {
+		return err
+	}
+	executor.SetVariables(vars)
+	if err := executor.Execute(ctx, wf); err != nil {
+		return err
+	}
+
+	if export != "" {
+		if err := executor.WriteExports(os.Stdout, export, wf); err != nil {
+			return err
+		}
+	}
+	if path := os.Getenv(exportFileEnv); path != "" {
+		return writeExportFile(executor, wf, path)
+	}
+	return nil
+}
+
+func writeExportFile(executor *workflow.Executor, wf *workflow.Workflow, path string) error {
+	shell := os.Getenv(exportShellEnv)
+	if shell == "" {
+		shell = workflow.ShellPOSIX
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to write exports: %w", err)
+	}
+	defer f.Close()
+	return executor.WriteExports(f, shell, wf)
+}
+
+// isTerminal reports whether f is a terminal. Checking for a character
+// device isn't enough, since /dev/null is one too.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd())
+}
+
 var runCmd = &cobra.Command{
-	Use:   "run [workflow-name]",
+	Use:   "run [workflow-name] [args...]",
 	Short: "Run a workflow by name",
-	Long:  "Execute a workflow directly by name, or show selection menu if no name provided",
+	Long: `Execute a workflow directly by name, or show selection menu if no name provided.
+Arguments after the name are parsed against the workflow's params; see
+'cmdr run <workflow-name> --help'.`,
+	ValidArgsFunction: completeRunArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		store, err := workflow.NewStore()
 		if err != nil {
@@ -30,18 +141,47 @@ var runCmd = &cobra.Command{
 		}
 
 		// Load the workflow
-		wf, err := store.Load(workflowName)
+		wf, err := store.LoadResolved(workflowName)
 		if err != nil {
 			return err
 		}
 
-		// Execute the workflow
-		executor := workflow.NewExecutor()
-		return executor.Execute(wf)
+		// Global flags like --plain can come after the params too.
+		fs := wf.FlagSet()
+		fs.AddFlagSet(cmd.InheritedFlags())
+		err = fs.Parse(args[1:])
+		if errors.Is(err, pflag.ErrHelp) {
+			fmt.Print(wf.Usage("cmdr run " + wf.Key))
+			return nil
+		}
+		var vars map[string]string
+		if err == nil {
+			vars, err = wf.Bind(fs, fs.Args())
+		}
+		if err != nil {
+			return fmt.Errorf("%w\nSee 'cmdr run %s --help'", err, wf.Key)
+		}
+
+		// Execute the workflow. From here on errors come from the run, not
+		// from how it was called.
+		cmd.SilenceUsage = true
+		export, _ := fs.GetString("export")
+		return runWorkflow(cmd.Context(), store, wf, vars, export)
 	},
 }
 
+// runSelected runs a workflow picked from a list, with its params left at
+// their defaults.
+func runSelected(ctx context.Context, store *workflow.Store, wf *workflow.Workflow) error {
+	resolved, err := store.Resolve(wf)
+	if err != nil {
+		return err
+	}
+	return runWorkflow(ctx, store, resolved, resolved.Defaults(), "")
+}
+
 func init() {
+	// Everything after the workflow name belongs to the workflow's params.
+	runCmd.Flags().SetInterspersed(false)
 	rootCmd.AddCommand(runCmd)
 }
-
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for workflow files",
+	Long: `Print the JSON Schema that workflow files, bundles and fragment libraries
+follow, for editors to validate and autocomplete them. Save it somewhere and
+point your editor at it, e.g. for the YAML language server:
+
+  cmdr schema > ~/.config/cmdr/workflows.schema.json
+
+and at the top of a workflow file:
+
+  # yaml-language-server: $schema=~/.config/cmdr/workflows.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(workflow.Schema)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/kevmul/cmdr/internal/ui"
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search workflows by name, description, tags and steps",
+	Long: `Search every workflow's name, key, description, tags and step prompts and
+commands, printing the matches best first. Prefix a word with # to require a tag.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := workflow.NewStore()
+		if err != nil {
+			return err
+		}
+
+		workflows, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		query := strings.Join(args, " ")
+		results := workflow.Search(workflows, query)
+		if len(results) == 0 {
+			fmt.Printf("No workflows match %q.\n", query)
+			return nil
+		}
+
+		for _, result := range results {
+			wf := workflows[result.Index]
+			fmt.Printf("%s %s\n", styles.TitleStyle.Render(wf.Name), styles.MutedTextStyle.Render("("+wf.Key+")"))
+			seen := make(map[string]bool)
+			for _, match := range result.Matches {
+				if match.Field == "name" || seen[match.Where()] {
+					continue
+				}
+				seen[match.Where()] = true
+				term := strings.TrimPrefix(match.Term, "#")
+				fmt.Printf("  %s %s\n", styles.MutedTextStyle.Render(match.Where()+":"), ui.HighlightTerm(match.Line(), term))
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Wrapper functions installed by `cmdr shell-init`. Each runs the real
+// binary with CMDR_EXPORT_FILE pointing at a temp file, then evaluates
+// whatever the run exported, so captured env vars and the workflow's cd
+// reach the user's shell.
+const (
+	posixWrapper = `cmdr() {
+  local __cmdr_file __cmdr_status
+  __cmdr_file="$(mktemp)" || return
+  CMDR_EXPORT_FILE="$__cmdr_file" CMDR_EXPORT_SHELL=sh command cmdr "$@"
+  __cmdr_status=$?
+  . "$__cmdr_file"
+  rm -f "$__cmdr_file"
+  return $__cmdr_status
+}
+`
+
+	fishWrapper = `function cmdr
+    set -l __cmdr_file (mktemp); or return
+    CMDR_EXPORT_FILE=$__cmdr_file CMDR_EXPORT_SHELL=fish command cmdr $argv
+    set -l __cmdr_status $status
+    source $__cmdr_file
+    rm -f $__cmdr_file
+    return $__cmdr_status
+end
+`
+
+	powerShellWrapper = `function cmdr {
+    $file = New-TemporaryFile
+    $env:CMDR_EXPORT_FILE = $file.FullName
+    $env:CMDR_EXPORT_SHELL = 'pwsh'
+    try {
+        & (Get-Command cmdr -CommandType Application | Select-Object -First 1) @args
+    } finally {
+        Remove-Item Env:CMDR_EXPORT_FILE, Env:CMDR_EXPORT_SHELL
+    }
+    $script = Get-Content -Raw $file.FullName
+    Remove-Item $file.FullName
+    if ($script) { Invoke-Expression $script }
+}
+`
+)
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init [bash|zsh|fish|pwsh]",
+	Short: "Print a shell function that applies workflow env changes",
+	Long: `Print a cmdr wrapper function for your shell. Through the wrapper, env vars
+captured by a workflow and its cd directory are applied to your shell
+after a successful run.
+
+  bash, zsh:   eval "$(cmdr shell-init bash)"
+  fish:        cmdr shell-init fish | source
+  pwsh:        Invoke-Expression (& cmdr shell-init pwsh | Out-String)`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "pwsh"},
+	Args:                  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := "bash"
+		if len(args) > 0 {
+			shell = args[0]
+		}
+
+		switch shell {
+		case "fish":
+			fmt.Print(fishWrapper)
+		case "pwsh":
+			fmt.Print(powerShellWrapper)
+		default:
+			fmt.Print(posixWrapper)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+}
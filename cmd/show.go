@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	showFormat   string
+	showResolved bool
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <workflow-name>",
+	Short: "Print a workflow's definition as YAML or JSON",
+	Long: `Print a workflow's definition as it's stored, or with --resolved as it runs:
+merged with the workflows it extends, its slots filled and its fragments
+expanded.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeWorkflowKeys(toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := workflow.ParseFormat(showFormat)
+		if err != nil {
+			return err
+		}
+
+		store, err := workflow.NewStore()
+		if err != nil {
+			return err
+		}
+		load := store.Load
+		if showResolved {
+			load = store.LoadResolved
+		}
+		wf, err := load(args[0])
+		if err != nil {
+			return err
+		}
+
+		data, err := workflow.Marshal(wf, format)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+func init() {
+	var formats []string
+	for _, f := range workflow.Formats {
+		formats = append(formats, string(f))
+	}
+
+	showCmd.Flags().StringVar(&showFormat, "format", string(workflow.FormatYAML), "output format: yaml or json")
+	showCmd.Flags().BoolVar(&showResolved, "resolved", false, "show the workflow with inheritance and fragments flattened")
+	showCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(formats, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(showCmd)
+}
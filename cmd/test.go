@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testVerbose bool
+	testRun     string
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [path...]",
+	Short: "Run workflow tests with mocked commands",
+	Long: `Run the tests in *_test.yaml files against the saved workflows. Paths may be
+files or directories, which are searched recursively; the default is the
+current directory.
+
+A test file names a workflow and lists test cases. Each case gives prompt
+answers, mocked command results matched by regexp, and the steps, variables
+and commands it expects:
+
+  workflow: deploy
+  tests:
+    - name: staging skips the backup
+      answers:
+        env: staging
+      mocks:
+        - match: ^kubectl apply
+          stdout: deployment.apps/api configured
+      expect:
+        ran: [1, "Apply manifests"]
+        skipped: ["Back up database"]
+        vars:
+          env: staging
+        commands:
+          - ^kubectl apply -f k8s/staging
+
+Nothing runs for real: a command without a matching mock fails its test.
+Commands are matched as written, without the strict-mode prelude. Built-in
+variables get fixed stand-ins, such as main for {{git.branch}}, which a
+case can override under builtins:
+
+      builtins:
+        git.branch: release/1.2`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var filter *regexp.Regexp
+		if testRun != "" {
+			re, err := regexp.Compile(testRun)
+			if err != nil {
+				return fmt.Errorf("invalid --run pattern: %w", err)
+			}
+			filter = re
+		}
+
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		paths, err := findTestFiles(args)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			fmt.Println("no test files")
+			return nil
+		}
+
+		store, err := workflow.NewStore()
+		if err != nil {
+			return err
+		}
+
+		failed := false
+		for _, path := range paths {
+			if !runTestFile(cmd, store, path, filter) {
+				failed = true
+			}
+		}
+		if failed {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return errors.New("tests failed")
+		}
+		return nil
+	},
+}
+
+// runTestFile runs a file's tests and prints their results the way go test
+// does, returning whether they all passed.
+func runTestFile(cmd *cobra.Command, store *workflow.Store, path string, filter *regexp.Regexp) bool {
+	started := time.Now()
+	fail := func(format string, args ...any) bool {
+		fmt.Printf("FAIL\t%s [%s]\n", path, fmt.Sprintf(format, args...))
+		return false
+	}
+
+	tf, err := workflow.LoadTestFile(path)
+	if err != nil {
+		return fail("load error: %v", err)
+	}
+	wf, err := store.LoadResolved(tf.Workflow)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	passed := true
+	ran := 0
+	for i := range tf.Tests {
+		tc := &tf.Tests[i]
+		name := testName(wf.Key, tc.Name)
+		if filter != nil && !filter.MatchString(name) {
+			continue
+		}
+		ran++
+
+		if testVerbose {
+			fmt.Printf("=== RUN   %s\n", name)
+		}
+		result := tc.Run(cmd.Context(), wf)
+		if result.Passed() {
+			if testVerbose {
+				fmt.Printf("--- PASS: %s (%.2fs)\n", name, result.Duration.Seconds())
+			}
+			continue
+		}
+
+		passed = false
+		fmt.Printf("--- FAIL: %s (%.2fs)\n", name, result.Duration.Seconds())
+		for _, failure := range result.Failures {
+			fmt.Printf("    %s\n", failure)
+		}
+		if testVerbose && result.Output != "" {
+			fmt.Println("    output:")
+			for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+				fmt.Printf("        %s\n", line)
+			}
+		}
+	}
+
+	elapsed := time.Since(started).Seconds()
+	switch {
+	case !passed:
+		fmt.Printf("FAIL\t%s\t%.3fs\n", path, elapsed)
+	case ran == 0:
+		fmt.Printf("ok  \t%s\t%.3fs [no tests to run]\n", path, elapsed)
+	default:
+		fmt.Printf("ok  \t%s\t%.3fs\n", path, elapsed)
+	}
+	return passed
+}
+
+// testName is how a test is shown and matched by --run, with spaces
+// replaced as go test does for subtests.
+func testName(key, name string) string {
+	return key + "/" + strings.ReplaceAll(name, " ", "_")
+}
+
+// findTestFiles expands directories into the *_test.yaml files under them.
+// Hidden directories are skipped.
+func findTestFiles(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != arg && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(d.Name(), "_test.yaml") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+func init() {
+	testCmd.Flags().BoolVarP(&testVerbose, "verbose", "v", false, "print every test as it runs, and the output of failing runs")
+	testCmd.Flags().StringVar(&testRun, "run", "", "only run tests whose workflow/name matches this regexp")
+	rootCmd.AddCommand(testCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check workflow files for mistakes",
+	Long: `Check workflow files, YAML or JSON, for syntax errors, unknown or misspelt
+fields, duplicate keys and steps missing what they need. A file may be a list
+of workflows like the store's or a bundle from 'cmdr export'; "-" reads
+stdin. With no files, the store and fragment library are checked, and every
+workflow is resolved to catch broken extends, slots and fragment uses.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		failed := false
+		if len(args) == 0 {
+			failed = !validateStore()
+		}
+		for _, path := range args {
+			if !validateFile(path) {
+				failed = true
+			}
+		}
+		if failed {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return errors.New("validation failed")
+		}
+		return nil
+	},
+}
+
+// validateStore checks the store's files and that each workflow resolves,
+// returning whether all is well.
+func validateStore() bool {
+	store, err := workflow.NewStore()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	ok := validateFile(store.Path())
+	if _, err := os.Stat(store.FragmentsPath()); err == nil {
+		ok = validateFile(store.FragmentsPath()) && ok
+	}
+	if !ok {
+		return false
+	}
+
+	workflows, errs, err := store.ListResolved()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+	for i, err := range errs {
+		if err != nil {
+			printInvalid(fmt.Sprintf("%s (resolved)", workflows[i].Key), err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// validateFile checks one file and prints the result, returning whether
+// it's valid.
+func validateFile(path string) bool {
+	var data []byte
+	var err error
+	format := workflow.FormatOf(path)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+		format = workflow.DetectFormat(data)
+		path = "stdin"
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	if isFragmentsFile(path) {
+		n, err := workflow.ValidateFragments(data, format)
+		if err != nil {
+			printInvalid(path, err)
+			return false
+		}
+		fmt.Printf("✅ %s: %d fragment(s) OK\n", path, n)
+		return true
+	}
+
+	workflows, err := workflow.ValidateFile(data, format)
+	if err != nil {
+		printInvalid(path, err)
+		return false
+	}
+	fmt.Printf("✅ %s: %d workflow(s) OK\n", path, len(workflows))
+	return true
+}
+
+// isFragmentsFile reports whether path is a fragment library, going by its
+// name: fragments.yaml, or anything ending in .fragments.yaml.
+func isFragmentsFile(path string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return name == "fragments" || strings.HasSuffix(name, ".fragments")
+}
+
+func printInvalid(name string, err error) {
+	fmt.Printf("❌ %s:\n", name)
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Printf("    %s\n", line)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
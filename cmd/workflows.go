@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"github.com/kevmul/cmdr/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+const workflowGroup = "workflows"
+
+// addWorkflowCommands registers each stored workflow as a subcommand, so
+// `cmdr deploy staging` is the same as `cmdr run deploy staging`.
+// Workflows whose key clashes with a built-in command are only reachable
+// through `cmdr run`, as are ones that don't resolve, so that it can report
+// why. A missing or broken store is left for the built-in commands to
+// report.
+func addWorkflowCommands(root *cobra.Command) {
+	store, err := workflow.NewStore()
+	if err != nil {
+		return
+	}
+	workflows, errs, err := store.ListResolved()
+	if err != nil {
+		return
+	}
+
+	reserved := map[string]bool{"help": true, "completion": true}
+	for _, c := range root.Commands() {
+		reserved[c.Name()] = true
+		for _, alias := range c.Aliases {
+			reserved[alias] = true
+		}
+	}
+
+	var commands []*cobra.Command
+	for i := range workflows {
+		wf := &workflows[i]
+		if reserved[wf.Key] || errs[i] != nil {
+			continue
+		}
+		commands = append(commands, newWorkflowCommand(store, wf))
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	root.AddGroup(&cobra.Group{ID: workflowGroup, Title: "Workflows:"})
+	root.AddCommand(commands...)
+}
+
+// newWorkflowCommand builds the subcommand for one workflow. Params become
+// its flags and arguments, and its help lists the prompts it will ask.
+func newWorkflowCommand(store *workflow.Store, wf *workflow.Workflow) *cobra.Command {
+	short := wf.Description
+	if short == "" {
+		short = wf.Name
+	}
+
+	cmd := &cobra.Command{
+		Use:     wf.Key + wf.ArgsUsage(),
+		Short:   short,
+		Long:    wf.Help(),
+		GroupID: workflowGroup,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			p, ok := wf.PositionalAt(len(args))
+			if !ok {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return optionCompletions(wf, p.Name), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vars, err := wf.Bind(cmd.Flags(), args)
+			if err != nil {
+				return err
+			}
+			// From here on errors come from the run, not from how it was called.
+			cmd.SilenceUsage = true
+			export, _ := cmd.Flags().GetString("export")
+			return runWorkflow(cmd.Context(), store, wf, vars, export)
+		},
+	}
+
+	cmd.Flags().AddFlagSet(wf.FlagSet())
+	cmd.RegisterFlagCompletionFunc("set", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeSet(wf, toComplete)
+	})
+	cmd.RegisterFlagCompletionFunc("export", cobra.FixedCompletions(workflow.ExportShells, cobra.ShellCompDirectiveNoFileComp))
+	for _, p := range wf.Params {
+		if p.Positional || len(wf.Options(p.Name)) == 0 {
+			continue
+		}
+		name := p.Name
+		cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return optionCompletions(wf, name), cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+
+	return cmd
+}
+
+// optionCompletions offers the options of the select step that sets
+// variable, described by their display text.
+func optionCompletions(wf *workflow.Workflow, variable string) []string {
+	var completions []string
+	for _, opt := range wf.Options(variable) {
+		value := opt.OptionValue()
+		if opt.Text != value {
+			value += "\t" + opt.Text
+		}
+		completions = append(completions, value)
+	}
+	return completions
+}
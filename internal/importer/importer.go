@@ -0,0 +1,183 @@
+// Package importer turns the tasks of other task runners (make targets,
+// npm scripts, just recipes and Taskfile tasks) into cmdr workflows.
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+)
+
+// Task is a target, script or recipe found in a task file.
+type Task struct {
+	Name        string
+	Description string
+	Deps        []string // tasks that run first
+	Args        []Arg
+}
+
+// Arg is a parameter a task takes on the command line.
+type Arg struct {
+	Name     string
+	Default  string
+	Optional bool // has a default, or takes zero or more values
+	Variadic bool // takes the remaining values; comes last
+}
+
+// Source is a kind of task file.
+type Source struct {
+	Name  string   // as given to --from
+	Files []string // file names looked for in a directory, in order
+	Parse func(data []byte) ([]Task, error)
+	// Command is the shell command that runs a task from file, which is
+	// the file's base name and may not be the tool's default.
+	Command func(file string, task Task) string
+}
+
+// Sources lists the supported task files.
+var Sources = []Source{makeSource, npmSource, justSource, taskfileSource}
+
+// Lookup returns the source called name.
+func Lookup(name string) (Source, error) {
+	var names []string
+	for _, src := range Sources {
+		if src.Name == name {
+			return src, nil
+		}
+		names = append(names, src.Name)
+	}
+	return Source{}, fmt.Errorf("unknown source %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// Find resolves path to a task file: path itself, or the first of the
+// source's files found in it when it is a directory.
+func (src Source) Find(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range src.Files {
+		file := filepath.Join(path, name)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("no %s found in %s", strings.Join(src.Files, " or "), path)
+}
+
+// Load finds and parses the task file at path.
+func (src Source) Load(path string) (file string, tasks []Task, err error) {
+	file, err = src.Find(path)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	tasks, err = src.Parse(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return file, tasks, nil
+}
+
+// Workflows converts the tasks of file into workflows. Each runs its task
+// through the task runner from the file's directory, so dependencies run
+// the way they always have. Names and keys start with prefix, usually the
+// project's directory name, to keep them apart from other projects' tasks.
+func (src Source) Workflows(file string, tasks []Task, prefix string) ([]workflow.Workflow, error) {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.Base(file)
+
+	workflows := make([]workflow.Workflow, 0, len(tasks))
+	for _, task := range tasks {
+		name := task.Name
+		if prefix != "" {
+			name = prefix + " " + task.Name
+		}
+
+		wf := workflow.Workflow{
+			Key:         workflow.Slugify(name),
+			Name:        name,
+			Description: describe(task),
+			Tags:        []string{src.Name},
+			Dir:         dir,
+			Steps: []workflow.Step{{
+				Type:    workflow.StepTypeCommand,
+				Command: src.Command(base, task),
+				Env:     argsEnv(task.Args),
+			}},
+		}
+		for _, arg := range task.Args {
+			wf.Params = append(wf.Params, workflow.Param{
+				Name:       arg.Name,
+				Positional: true,
+				Variadic:   arg.Variadic,
+				Default:    arg.Default,
+				Required:   !arg.Optional,
+			})
+		}
+		if err := wf.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", task.Name, err)
+		}
+		workflows = append(workflows, wf)
+	}
+	return workflows, nil
+}
+
+// describe is a task's description, noting its dependencies.
+func describe(task Task) string {
+	if len(task.Deps) == 0 {
+		return task.Description
+	}
+	deps := "depends on " + strings.Join(task.Deps, ", ")
+	if task.Description == "" {
+		return strings.ToUpper(deps[:1]) + deps[1:]
+	}
+	return task.Description + " (" + deps + ")"
+}
+
+// argWords are the shell words that pass a task's args on to it, from the
+// env vars argsEnv sets. Each is quoted, so an empty value or one with
+// spaces is still one argument, except a variadic arg, which splits back
+// into its values.
+func argWords(args []Arg) string {
+	var sb strings.Builder
+	for _, arg := range args {
+		if arg.Variadic {
+			sb.WriteString(" $" + argVar(arg.Name))
+		} else {
+			sb.WriteString(` "$` + argVar(arg.Name) + `"`)
+		}
+	}
+	return sb.String()
+}
+
+// argsEnv sets an env var to each arg's value for argWords, so the shell
+// never parses the values themselves.
+func argsEnv(args []Arg) map[string]string {
+	if len(args) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(args))
+	for _, arg := range args {
+		env[argVar(arg.Name)] = "{{" + arg.Name + "}}"
+	}
+	return env
+}
+
+// argVar is the env var holding an arg's value, e.g. ARG_DRY_RUN for
+// dry-run.
+func argVar(name string) string {
+	return "ARG_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
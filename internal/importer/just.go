@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var justSource = Source{
+	Name:  "just",
+	Files: []string{"justfile", "Justfile", ".justfile"},
+	Parse: parseJustfile,
+	Command: func(file string, task Task) string {
+		switch file {
+		case "justfile", "Justfile", ".justfile":
+			return "just " + task.Name + argWords(task.Args)
+		}
+		return "just --justfile " + file + " " + task.Name + argWords(task.Args)
+	},
+}
+
+var (
+	// justRecipe matches a recipe header: an optional @, the name, its
+	// parameters, a colon and its dependencies.
+	justRecipe  = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)((?:\s+[^:]*?)?)\s*:([^=].*)?$`)
+	justParam   = regexp.MustCompile(`^([+*$]*)([A-Za-z_][A-Za-z0-9_-]*)(?:=(.*))?$`)
+	justDocAttr = regexp.MustCompile(`^\[doc\(\s*['"](.*)['"]\s*\)\]$`)
+)
+
+// parseJustfile finds a justfile's public recipes. A recipe's description
+// is the comment on the line above it or its [doc] attribute, its
+// parameters become args, and its dependencies are the recipes listed
+// after the colon. Private recipes, those starting with _ or marked
+// [private], are skipped.
+func parseJustfile(data []byte) ([]Task, error) {
+	var tasks []Task
+	var doc string
+	private := false
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			doc, private = "", false
+			continue
+		case line[0] == ' ' || line[0] == '\t':
+			// Recipe body.
+			continue
+		case strings.HasPrefix(line, "#"):
+			doc = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		case strings.HasPrefix(line, "["):
+			if m := justDocAttr.FindStringSubmatch(line); m != nil {
+				doc = m[1]
+			}
+			if strings.Contains(line, "private") {
+				private = true
+			}
+			continue
+		}
+
+		m := justRecipe.FindStringSubmatch(line)
+		if m == nil || isJustStatement(m[1]) {
+			doc, private = "", false
+			continue
+		}
+		name := m[1]
+		if private || strings.HasPrefix(name, "_") {
+			doc, private = "", false
+			continue
+		}
+
+		tasks = append(tasks, Task{
+			Name:        name,
+			Description: doc,
+			Deps:        justDeps(m[3]),
+			Args:        justArgs(m[2]),
+		})
+		doc, private = "", false
+	}
+	return tasks, nil
+}
+
+// isJustStatement reports whether a line starting with word is a setting
+// or other statement rather than a recipe.
+func isJustStatement(word string) bool {
+	switch word {
+	case "set", "alias", "export", "import", "mod":
+		return true
+	}
+	return false
+}
+
+// justArgs parses recipe parameters such as `env tag='latest' +files`.
+// Positional args can't be left out before a later one is given, so those
+// after an optional one are optional too. A +variadic or *variadic one,
+// which just only allows last, takes the rest of the values.
+func justArgs(s string) []Arg {
+	var args []Arg
+	optional := false
+	for _, field := range splitJustFields(s) {
+		m := justParam.FindStringSubmatch(field)
+		if m == nil {
+			continue
+		}
+		optional = optional || m[3] != "" || strings.Contains(m[1], "*")
+		args = append(args, Arg{
+			Name:     m[2],
+			Default:  strings.Trim(m[3], `'"`),
+			Optional: optional,
+			Variadic: strings.ContainsAny(m[1], "+*"),
+		})
+	}
+	return args
+}
+
+// justDeps parses the dependencies after a recipe's colon, including
+// subsequent dependencies after && and ones called with arguments.
+func justDeps(s string) []string {
+	s, _, _ = strings.Cut(s, "#")
+	var deps []string
+	for _, field := range splitJustFields(strings.ReplaceAll(s, "&&", " ")) {
+		field = strings.TrimPrefix(field, "(")
+		name, _, _ := strings.Cut(field, " ")
+		if name != "" {
+			deps = append(deps, name)
+		}
+	}
+	return deps
+}
+
+// splitJustFields splits on spaces outside quotes and parentheses.
+func splitJustFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+	depth := 0
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			continue
+		case (r == ' ' || r == '\t') && depth == 0:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var makeSource = Source{
+	Name:  "make",
+	Files: []string{"GNUmakefile", "makefile", "Makefile"},
+	Parse: parseMakefile,
+	Command: func(file string, task Task) string {
+		switch file {
+		case "GNUmakefile", "makefile", "Makefile":
+			return "make " + task.Name
+		}
+		return "make -f " + file + " " + task.Name
+	},
+}
+
+var (
+	// makeRule matches a rule line: targets, a colon, then prerequisites
+	// and an optional "## description".
+	makeRule = regexp.MustCompile(`^([^\s:=#][^:=#]*?)\s*::?\s*([^=#]*?)\s*(?:##\s*(.*))?$`)
+	makeDoc  = regexp.MustCompile(`^##\s?(.*)$`)
+)
+
+// parseMakefile finds a Makefile's explicit targets. A target's description
+// is a "## " comment on the line above it or at the end of its rule, and its
+// dependencies are the prerequisites that are targets themselves. Special
+// targets such as .PHONY, pattern rules and variable assignments are
+// skipped.
+func parseMakefile(data []byte) ([]Task, error) {
+	var tasks []Task
+	index := make(map[string]int)
+	var doc string
+
+	for _, line := range joinContinuations(string(data)) {
+		if m := makeDoc.FindStringSubmatch(line); m != nil {
+			doc = strings.TrimSpace(m[1])
+			continue
+		}
+		if strings.HasPrefix(line, "\t") || isAssignment(line) {
+			doc = ""
+			continue
+		}
+
+		m := makeRule.FindStringSubmatch(line)
+		if m == nil {
+			doc = ""
+			continue
+		}
+		desc := strings.TrimSpace(m[3])
+		if desc == "" {
+			desc = doc
+		}
+		doc = ""
+
+		prereqs, _, _ := strings.Cut(m[2], "|") // drop order-only prerequisites
+		for _, target := range strings.Fields(m[1]) {
+			if strings.HasPrefix(target, ".") || strings.ContainsAny(target, "%$") {
+				continue
+			}
+			if i, ok := index[target]; ok {
+				// A target can be named by several rules; merge them.
+				if tasks[i].Description == "" {
+					tasks[i].Description = desc
+				}
+				tasks[i].Deps = append(tasks[i].Deps, strings.Fields(prereqs)...)
+				continue
+			}
+			index[target] = len(tasks)
+			tasks = append(tasks, Task{Name: target, Description: desc, Deps: strings.Fields(prereqs)})
+		}
+	}
+
+	// Only other targets count as dependencies, not source files.
+	for i := range tasks {
+		var deps []string
+		seen := make(map[string]bool)
+		for _, dep := range tasks[i].Deps {
+			if _, ok := index[dep]; ok && !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+		tasks[i].Deps = deps
+	}
+	return tasks, nil
+}
+
+// joinContinuations splits a Makefile into lines, joining lines that end in
+// a backslash with the next.
+func joinContinuations(s string) []string {
+	var lines []string
+	var cur strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasSuffix(line, `\`) {
+			cur.WriteString(strings.TrimSuffix(line, `\`) + " ")
+			continue
+		}
+		cur.WriteString(line)
+		lines = append(lines, cur.String())
+		cur.Reset()
+	}
+	return lines
+}
+
+// isAssignment reports whether a line sets a variable, including the
+// target-specific "target: VAR = value" form, or is a directive.
+func isAssignment(line string) bool {
+	for _, prefix := range []string{"export ", "override ", "define ", "include ", "-include ", "ifeq", "ifneq", "ifdef", "ifndef", "else", "endif"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	line, _, _ = strings.Cut(line, "#")
+	return strings.Contains(line, "=")
+}
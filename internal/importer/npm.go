@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var npmSource = Source{
+	Name:  "npm",
+	Files: []string{"package.json"},
+	Parse: parsePackageJSON,
+	Command: func(file string, task Task) string {
+		return "npm run " + task.Name
+	},
+}
+
+// parsePackageJSON reads a package.json's scripts in the order they are
+// written. Descriptions come from a "scripts-info" or "scriptsDescription"
+// object keyed by script name. npm runs a script's pre and post scripts
+// with it, so those are folded into the script as dependencies rather
+// than imported on their own.
+func parsePackageJSON(data []byte) ([]Task, error) {
+	var pkg struct {
+		Scripts            json.RawMessage   `json:"scripts"`
+		ScriptsInfo        map[string]string `json:"scripts-info"`
+		ScriptsDescription map[string]string `json:"scriptsDescription"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	if len(pkg.Scripts) == 0 {
+		return nil, nil
+	}
+
+	names, err := objectKeys(pkg.Scripts)
+	if err != nil {
+		return nil, fmt.Errorf("scripts: %w", err)
+	}
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		exists[name] = true
+	}
+
+	var tasks []Task
+	for _, name := range names {
+		if hook, ok := hookFor(name, exists); ok && hook != name {
+			continue
+		}
+		desc := pkg.ScriptsInfo[name]
+		if desc == "" {
+			desc = pkg.ScriptsDescription[name]
+		}
+		task := Task{Name: name, Description: desc}
+		if exists["pre"+name] {
+			task.Deps = append(task.Deps, "pre"+name)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// hookFor returns the script that name is a pre or post hook of.
+func hookFor(name string, exists map[string]bool) (string, bool) {
+	for _, prefix := range []string{"pre", "post"} {
+		if base, ok := strings.CutPrefix(name, prefix); ok && exists[base] {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// objectKeys returns a JSON object's keys in document order.
+func objectKeys(raw json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("not an object")
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
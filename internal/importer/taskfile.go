@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var taskfileSource = Source{
+	Name:  "taskfile",
+	Files: []string{"Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml"},
+	Parse: parseTaskfile,
+	Command: func(file string, task Task) string {
+		switch file {
+		case "Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml":
+			return "task " + task.Name
+		}
+		return "task --taskfile " + file + " " + task.Name
+	},
+}
+
+// taskfileTask is the part of a Taskfile task that's imported. A task can
+// also be written as just a command or a list of them, which has none of
+// these.
+type taskfileTask struct {
+	Desc     string      `yaml:"desc"`
+	Summary  string      `yaml:"summary"`
+	Internal bool        `yaml:"internal"`
+	Deps     []yaml.Node `yaml:"deps"`
+}
+
+// parseTaskfile reads a Taskfile's tasks in the order they are written,
+// with their desc (or the first line of their summary) and deps. Internal
+// tasks are skipped.
+func parseTaskfile(data []byte) ([]Task, error) {
+	var doc struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Tasks.Kind == 0 {
+		return nil, nil
+	}
+	if doc.Tasks.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: tasks must be a mapping", doc.Tasks.Line)
+	}
+
+	var tasks []Task
+	for i := 0; i+1 < len(doc.Tasks.Content); i += 2 {
+		name, node := doc.Tasks.Content[i].Value, doc.Tasks.Content[i+1]
+
+		var tt taskfileTask
+		if node.Kind == yaml.MappingNode {
+			if err := node.Decode(&tt); err != nil {
+				return nil, fmt.Errorf("task %s: %w", name, err)
+			}
+		}
+		if tt.Internal {
+			continue
+		}
+
+		desc := tt.Desc
+		if desc == "" {
+			desc, _, _ = strings.Cut(strings.TrimSpace(tt.Summary), "\n")
+		}
+		task := Task{Name: name, Description: desc}
+		for _, dep := range tt.Deps {
+			// A dep is a task name or {task: name, vars: ...}.
+			switch dep.Kind {
+			case yaml.ScalarNode:
+				task.Deps = append(task.Deps, dep.Value)
+			case yaml.MappingNode:
+				var call struct {
+					Task string `yaml:"task"`
+				}
+				if err := dep.Decode(&call); err == nil && call.Task != "" {
+					task.Deps = append(task.Deps, call.Task)
+				}
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
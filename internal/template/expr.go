@@ -0,0 +1,284 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// ─── Expressions ──────────────────────────────────────────────────────────────
+//
+//	pipeline := command { "|" command }
+//	command  := operand { operand }
+//	operand  := name | string | number | "(" pipeline ")"
+//
+// A command of several operands, or one that is piped into, calls the
+// function its first operand names. A lone name is a variable.
+
+type tokenKind int
+
+const (
+	tokName tokenKind = iota
+	tokString
+	tokNumber
+	tokPipe
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind tokenKind
+	text string // the name, or the value of a string or number
+}
+
+func isNameStart(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || unicode.IsDigit(r) || r == '.' || r == '-'
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '|':
+			tokens = append(tokens, token{kind: tokPipe})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokOpen})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokClose})
+			i++
+		case r == '"' || r == '`':
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				if r == '"' && rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(rs) {
+				return nil, errors.New("unterminated string")
+			}
+			value, err := strconv.Unquote(string(rs[i : j+1]))
+			if err != nil {
+				return nil, fmt.Errorf("bad string %s", string(rs[i:j+1]))
+			}
+			tokens = append(tokens, token{kind: tokString, text: value})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i + 1
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			text := string(rs[i:j])
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, fmt.Errorf("bad number %s", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text})
+			i = j
+		case isNameStart(r):
+			j := i + 1
+			for j < len(rs) && isNameChar(rs[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokName, text: string(rs[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+type pipeline struct {
+	commands []command
+}
+
+type command struct {
+	operands []operand
+}
+
+// operand is a name, a literal or a parenthesised pipeline.
+type operand struct {
+	name    string
+	literal *string
+	sub     *pipeline
+}
+
+func parse(s string) (*pipeline, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	p := exprParser{tokens: tokens}
+	pl, err := p.pipeline()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.New("unexpected )")
+	}
+	return pl, nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) pipeline() (*pipeline, error) {
+	pl := &pipeline{}
+	for {
+		cmd, err := p.command()
+		if err != nil {
+			return nil, err
+		}
+		pl.commands = append(pl.commands, cmd)
+		if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokPipe {
+			p.pos++
+			continue
+		}
+		return pl, nil
+	}
+}
+
+func (p *exprParser) command() (command, error) {
+	var cmd command
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		switch tok.kind {
+		case tokPipe, tokClose:
+			if len(cmd.operands) == 0 {
+				return cmd, errors.New("missing value")
+			}
+			return cmd, nil
+		case tokName:
+			cmd.operands = append(cmd.operands, operand{name: tok.text})
+			p.pos++
+		case tokString, tokNumber:
+			cmd.operands = append(cmd.operands, operand{literal: &tok.text})
+			p.pos++
+		case tokOpen:
+			p.pos++
+			sub, err := p.pipeline()
+			if err != nil {
+				return cmd, err
+			}
+			if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokClose {
+				return cmd, errors.New("missing )")
+			}
+			p.pos++
+			cmd.operands = append(cmd.operands, operand{sub: sub})
+		}
+	}
+	if len(cmd.operands) == 0 {
+		return cmd, errors.New("missing value")
+	}
+	return cmd, nil
+}
+
+// value is the result of evaluating an operand. An unset variable is
+// undefined rather than an error straight away, so default can replace it.
+type value struct {
+	s         string
+	name      string
+	undefined bool
+}
+
+func (pl *pipeline) eval(p *Parser) (value, error) {
+	var in *value
+	for _, cmd := range pl.commands {
+		v, err := cmd.eval(p, in)
+		if err != nil {
+			return value{}, err
+		}
+		in = &v
+	}
+	return *in, nil
+}
+
+func (cmd command) eval(p *Parser, in *value) (value, error) {
+	first := cmd.operands[0]
+	if len(cmd.operands) == 1 && in == nil {
+		return first.eval(p)
+	}
+	if first.name == "" {
+		return value{}, errors.New("only functions take arguments")
+	}
+	fn, ok := functions[first.name]
+	if !ok {
+		return value{}, fmt.Errorf("unknown function %q", first.name)
+	}
+
+	var args []value
+	for _, o := range cmd.operands[1:] {
+		v, err := o.eval(p)
+		if err != nil {
+			return value{}, err
+		}
+		args = append(args, v)
+	}
+	if in != nil {
+		args = append(args, *in)
+	}
+	return fn.call(first.name, args)
+}
+
+func (o operand) eval(p *Parser) (value, error) {
+	switch {
+	case o.literal != nil:
+		return value{s: *o.literal}, nil
+	case o.sub != nil:
+		return o.sub.eval(p)
+	}
+	if s, ok := p.Get(o.name); ok {
+		return value{s: s, name: o.name}, nil
+	}
+	if p.partial {
+		return value{}, fmt.Errorf("%q isn't set", o.name)
+	}
+	return value{name: o.name, undefined: true}, nil
+}
+
+// check reports calls to unknown functions and wrong argument counts.
+func (pl *pipeline) check() error {
+	for i, cmd := range pl.commands {
+		piped := i > 0
+		for _, o := range cmd.operands {
+			if o.sub != nil {
+				if err := o.sub.check(); err != nil {
+					return err
+				}
+			}
+		}
+		if len(cmd.operands) == 1 && !piped {
+			continue
+		}
+		first := cmd.operands[0]
+		if first.name == "" {
+			return errors.New("only functions take arguments")
+		}
+		fn, ok := functions[first.name]
+		if !ok {
+			return fmt.Errorf("unknown function %q", first.name)
+		}
+		n := len(cmd.operands) - 1
+		if piped {
+			n++
+		}
+		if err := fn.checkArgs(first.name, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
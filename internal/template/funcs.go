@@ -0,0 +1,247 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ─── Functions ────────────────────────────────────────────────────────────────
+
+type function struct {
+	args int
+	// lenient functions accept unset variables as empty strings.
+	lenient bool
+	f       func(args []string) (string, error)
+}
+
+var functions = map[string]function{
+	// Arithmetic. Integers stay integers, in 64 bits, so div truncates; any
+	// decimal argument makes the result decimal.
+	"add": {args: 2, f: arith(addInt, func(a, b float64) (float64, error) { return a + b, nil })},
+	"sub": {args: 2, f: arith(subInt, func(a, b float64) (float64, error) { return a - b, nil })},
+	"mul": {args: 2, f: arith(mulInt, func(a, b float64) (float64, error) { return a * b, nil })},
+	"div": {args: 2, f: arith(divInt, func(a, b float64) (float64, error) {
+		if b == 0 {
+			return 0, errDivZero
+		}
+		return a / b, nil
+	})},
+	"mod": {args: 2, f: arith(modInt, func(a, b float64) (float64, error) {
+		if b == 0 {
+			return 0, errDivZero
+		}
+		return math.Mod(a, b), nil
+	})},
+	"min": {args: 2, f: arith(
+		func(a, b int64) (int64, error) { return min(a, b), nil },
+		func(a, b float64) (float64, error) { return min(a, b), nil },
+	)},
+	"max": {args: 2, f: arith(
+		func(a, b int64) (int64, error) { return max(a, b), nil },
+		func(a, b float64) (float64, error) { return max(a, b), nil },
+	)},
+
+	// Strings. The string being worked on comes last, so it can be piped in.
+	"upper":      {args: 1, f: func(a []string) (string, error) { return strings.ToUpper(a[0]), nil }},
+	"lower":      {args: 1, f: func(a []string) (string, error) { return strings.ToLower(a[0]), nil }},
+	"title":      {args: 1, f: func(a []string) (string, error) { return title(a[0]), nil }},
+	"trim":       {args: 1, f: func(a []string) (string, error) { return strings.TrimSpace(a[0]), nil }},
+	"trimPrefix": {args: 2, f: func(a []string) (string, error) { return strings.TrimPrefix(a[1], a[0]), nil }},
+	"trimSuffix": {args: 2, f: func(a []string) (string, error) { return strings.TrimSuffix(a[1], a[0]), nil }},
+	"replace":    {args: 3, f: func(a []string) (string, error) { return strings.ReplaceAll(a[2], a[0], a[1]), nil }},
+	"slug":       {args: 1, f: func(a []string) (string, error) { return slug(a[0]), nil }},
+	"len":        {args: 1, f: func(a []string) (string, error) { return strconv.Itoa(len([]rune(a[0]))), nil }},
+	"substr":     {args: 3, f: substr},
+	"date":       {args: 2, f: date},
+	"default": {args: 2, lenient: true, f: func(a []string) (string, error) {
+		if a[1] == "" {
+			return a[0], nil
+		}
+		return a[1], nil
+	}},
+}
+
+func (fn function) checkArgs(name string, n int) error {
+	if n != fn.args {
+		return fmt.Errorf("%s takes %d argument(s), got %d", name, fn.args, n)
+	}
+	return nil
+}
+
+func (fn function) call(name string, args []value) (value, error) {
+	if err := fn.checkArgs(name, len(args)); err != nil {
+		return value{}, err
+	}
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		if arg.undefined && !fn.lenient {
+			return value{}, fmt.Errorf("%q isn't set", arg.name)
+		}
+		strs[i] = arg.s
+	}
+	s, err := fn.f(strs)
+	if err != nil {
+		return value{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return value{s: s}, nil
+}
+
+// arith wraps a binary operation on numbers: ints when both are
+// integers, floats otherwise.
+func arith(ints func(a, b int64) (int64, error), floats func(a, b float64) (float64, error)) func([]string) (string, error) {
+	return func(args []string) (string, error) {
+		a, err := number(args[0])
+		if err != nil {
+			return "", err
+		}
+		b, err := number(args[1])
+		if err != nil {
+			return "", err
+		}
+		if a.isInt && b.isInt {
+			result, err := ints(a.i, b.i)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(result, 10), nil
+		}
+		result, err := floats(a.f, b.f)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(result, 'f', -1, 64), nil
+	}
+}
+
+// num is a parsed number, with its exact value if it's an integer.
+type num struct {
+	i     int64
+	f     float64
+	isInt bool
+}
+
+func number(s string) (num, error) {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return num{i: i, f: float64(i), isInt: true}, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return num{}, fmt.Errorf("%q isn't a number", s)
+	}
+	return num{f: f}, nil
+}
+
+var (
+	errDivZero  = errors.New("division by zero")
+	errOverflow = errors.New("result overflows a 64-bit integer")
+)
+
+func addInt(a, b int64) (int64, error) {
+	c := a + b
+	if (c > a) != (b > 0) {
+		return 0, errOverflow
+	}
+	return c, nil
+}
+
+func subInt(a, b int64) (int64, error) {
+	c := a - b
+	if (c < a) != (b > 0) {
+		return 0, errOverflow
+	}
+	return c, nil
+}
+
+func mulInt(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, errOverflow
+	}
+	return c, nil
+}
+
+func divInt(a, b int64) (int64, error) {
+	switch {
+	case b == 0:
+		return 0, errDivZero
+	case a == math.MinInt64 && b == -1:
+		return 0, errOverflow
+	}
+	return a / b, nil
+}
+
+func modInt(a, b int64) (int64, error) {
+	if b == 0 {
+		return 0, errDivZero
+	}
+	return a % b, nil
+}
+
+func title(s string) string {
+	rs := []rune(s)
+	for i, r := range rs {
+		if i == 0 || unicode.IsSpace(rs[i-1]) {
+			rs[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(rs)
+}
+
+// slug lowercases s and joins its words with dashes, as workflow keys are.
+func slug(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// substr returns the runes of s from start up to end, clamped to its
+// length.
+func substr(args []string) (string, error) {
+	start, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", fmt.Errorf("start %q isn't a whole number", args[0])
+	}
+	end, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", fmt.Errorf("end %q isn't a whole number", args[1])
+	}
+	rs := []rune(args[2])
+	start = max(0, min(start, len(rs)))
+	end = max(start, min(end, len(rs)))
+	return string(rs[start:end]), nil
+}
+
+// date formats a time, such as {{now}}, with a Go layout: "2006-01-02" for
+// a date, "15:04" for a time of day. The time is RFC 3339 or Unix seconds.
+func date(args []string) (string, error) {
+	layout, s := args[0], strings.TrimSpace(args[1])
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		secs, serr := strconv.ParseInt(s, 10, 64)
+		if serr != nil {
+			return "", fmt.Errorf("%q isn't a time", s)
+		}
+		t = time.Unix(secs, 0)
+	}
+	return t.Format(layout), nil
+}
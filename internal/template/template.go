@@ -1,12 +1,22 @@
 package template
 
 import (
+	"fmt"
 	"strings"
 )
 
-// Parser handles variable interpolation in strings
+// Parser handles variable interpolation in strings.
+//
+// A placeholder is {{name}} or an expression such as
+// {{registry}}/{{service | lower}} or {{add build 1}}: a variable, string
+// or number, optionally piped through functions. The piped value is the
+// function's last argument, as in Go templates.
 type Parser struct {
 	variables map[string]string
+	fallback  func(name string) (string, bool)
+
+	// partial is set while evaluating for Partial.
+	partial bool
 }
 
 // NewParser creates a new template parser
@@ -23,21 +33,129 @@ func (p *Parser) Set(key, value string) {
 
 // Get gets a variable value
 func (p *Parser) Get(key string) (string, bool) {
-	val, ok := p.variables[key]
-	return val, ok
+	if val, ok := p.variables[key]; ok {
+		return val, true
+	}
+	if p.fallback != nil {
+		return p.fallback(key)
+	}
+	return "", false
 }
 
-// Parse replaces all {{variable}} placeholders with their values
+// SetFallback sets a function that provides variables nothing has Set,
+// such as built-ins computed on demand. Reset keeps it.
+func (p *Parser) SetFallback(f func(name string) (string, bool)) {
+	p.fallback = f
+}
+
+// Parse replaces all {{variable}} placeholders with their values.
+// Placeholders that don't evaluate, such as unset variables or the
+// {{.Names}} of a docker --format template, are left as they are.
 func (p *Parser) Parse(template string) string {
-	result := template
-	for key, value := range p.variables {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-	return result
+	out, _ := p.expand(template, false)
+	return out
+}
+
+// Execute is Parse, but fails on placeholders that don't evaluate.
+func (p *Parser) Execute(template string) (string, error) {
+	return p.expand(template, true)
+}
+
+// Partial is Parse, but leaves placeholders alone if they use any variable
+// that isn't set, even through default. It fills in what's known ahead of
+// a run without deciding anything the run will.
+func (p *Parser) Partial(template string) string {
+	q := *p
+	q.partial, q.fallback = true, nil
+	out, _ := q.expand(template, false)
+	return out
 }
 
 // Reset clears all variables
 func (p *Parser) Reset() {
 	p.variables = make(map[string]string)
 }
+
+// Check reports syntax errors and unknown functions in the placeholders
+// of template, without evaluating it.
+func Check(template string) error {
+	return eachPlaceholder(template, func(inner string) error {
+		pl, err := parse(inner)
+		if err != nil {
+			return err
+		}
+		return pl.check()
+	})
+}
+
+func (p *Parser) expand(template string, strict bool) (string, error) {
+	var sb strings.Builder
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start+2:], "}}")
+		if end < 0 {
+			if strict {
+				return "", fmt.Errorf("unclosed {{ in %q", template)
+			}
+			break
+		}
+		inner := rest[start+2 : start+2+end]
+		sb.WriteString(rest[:start])
+		value, err := p.eval(inner)
+		switch {
+		case err == nil:
+			sb.WriteString(value)
+		case strict:
+			return "", fmt.Errorf("{{%s}}: %w", inner, err)
+		default:
+			sb.WriteString(rest[start : start+4+end])
+		}
+		rest = rest[start+4+end:]
+	}
+	sb.WriteString(rest)
+	return sb.String(), nil
+}
+
+// eval evaluates the text between a pair of braces.
+func (p *Parser) eval(inner string) (string, error) {
+	// Variables are looked up as written first, so names that aren't
+	// valid in expressions keep working.
+	if value, ok := p.Get(inner); ok {
+		return value, nil
+	}
+	pl, err := parse(inner)
+	if err != nil {
+		return "", err
+	}
+	v, err := pl.eval(p)
+	if err != nil {
+		return "", err
+	}
+	if v.undefined {
+		return "", fmt.Errorf("%q isn't set", v.name)
+	}
+	return v.s, nil
+}
+
+func eachPlaceholder(template string, f func(inner string) error) error {
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return nil
+		}
+		end := strings.Index(rest[start+2:], "}}")
+		if end < 0 {
+			return fmt.Errorf("unclosed {{ in %q", template)
+		}
+		inner := rest[start+2 : start+2+end]
+		if err := f(inner); err != nil {
+			return fmt.Errorf("{{%s}}: %w", inner, err)
+		}
+		rest = rest[start+4+end:]
+	}
+}
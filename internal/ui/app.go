@@ -2,12 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/kevmul/cmdr/internal/styles"
 	"github.com/kevmul/cmdr/internal/workflow"
 
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -19,11 +19,26 @@ var (
 )
 
 type keyMap struct {
-	Run  key.Binding
-	Quit key.Binding
+	New    key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Run    key.Binding
+	Quit   key.Binding
 }
 
 var keys = keyMap{
+	New: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete"),
+	),
 	Run: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("↵", "run"),
@@ -34,22 +49,31 @@ var keys = keyMap{
 	),
 }
 
-type workflowItem struct {
-	workflow.Workflow
-}
+type mode int
 
-func (i workflowItem) Title() string {
-	key := styles.MutedTextStyle.Render(fmt.Sprintf("(%s)", i.Key))
-	return fmt.Sprintf("%s %s", i.Name, key)
-}
-func (i workflowItem) Description() string { return i.Workflow.Description }
-func (i workflowItem) FilterValue() string { return i.Name }
+const (
+	modeList mode = iota
+	modeConfirmDelete
+	modeBuilder
+	modeEditError
+)
 
 type mainModel struct {
-	list     list.Model
+	list     WorkflowList
 	store    *workflow.Store
 	selected *workflow.Workflow
 	action   string // "run", "edit", "delete", "create", ""
+	mode     mode
+
+	builder builderModel
+
+	// The workflow being edited in $EDITOR and the temp file holding it.
+	editKey  string
+	editPath string
+	editErr  error
+
+	status    string
+	statusErr bool
 
 	width  int
 	height int
@@ -58,29 +82,11 @@ type mainModel struct {
 }
 
 func NewMainModel(store *workflow.Store) (tea.Model, error) {
-
-	workflows, err := store.List()
+	l, err := NewWorkflowList(store)
 	if err != nil {
 		return nil, err
 	}
 
-	items := make([]list.Item, len(workflows))
-	for i, w := range workflows {
-		items[i] = workflowItem{w}
-	}
-
-	d := list.NewDefaultDelegate()
-	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(styles.Primary).BorderLeftForeground(styles.Primary)
-	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(styles.Tertiary).BorderLeftForeground(styles.Primary)
-
-	l := list.New(items, d, 0, 0)
-	l.Title = "Command Runner"
-	l.SetShowTitle(false)
-	l.SetShowStatusBar(true)
-	l.SetFilteringEnabled(true)
-	l.SetShowHelp(false)
-	l.SetShowHelp(false)
-
 	return &mainModel{
 		list:  l,
 		store: store,
@@ -92,38 +98,224 @@ func (m *mainModel) Init() tea.Cmd {
 	return nil
 }
 
+// refresh reloads the list from the store, selecting the workflow with the
+// given key if it is still present.
+func (m *mainModel) refresh(selectKey string) {
+	m.list.ResetFilter()
+	if err := m.list.Reload(); err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+	m.list.Select(selectKey)
+}
+
+func (m *mainModel) setStatus(status string, isErr bool) {
+	m.status = status
+	m.statusErr = isErr
+}
+
 func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
 		h, v := lipgloss.NewStyle().GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v-4)
+		m.list.SetSize(msg.Width-h-PreviewWidth(msg.Width), msg.Height-v-5)
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
+		if m.mode == modeBuilder {
+			m.builder, _ = m.builder.Update(msg)
+		}
 		return m, nil
 
+	case editorFinishedMsg:
+		return m.finishEdit(msg.err)
+
 	case tea.KeyMsg:
+		switch m.mode {
+		case modeConfirmDelete:
+			return m.updateConfirmDelete(msg)
+		case modeBuilder:
+			return m.updateBuilder(msg)
+		case modeEditError:
+			return m.updateEditError(msg)
+		}
+
+		// Let the list have every key while the user is typing a filter.
+		if m.list.Filtering() {
+			break
+		}
+
+		m.status = ""
 		switch {
 		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
 
 		case key.Matches(msg, keys.Run):
-			if item, ok := m.list.SelectedItem().(workflowItem); ok {
-				m.selected = &item.Workflow
+			if wf := m.list.Selected(); wf != nil {
+				m.selected = wf
 				m.action = "run"
 				return m, tea.Quit
 			}
+
+		case key.Matches(msg, keys.New):
+			m.builder = newBuilderModel(m.store)
+			m.builder, _ = m.builder.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+			m.mode = modeBuilder
+			return m, m.builder.Init()
+
+		case key.Matches(msg, keys.Edit):
+			if wf := m.list.Selected(); wf != nil {
+				return m.startEdit(wf)
+			}
+
+		case key.Matches(msg, keys.Delete):
+			if m.list.Selected() != nil {
+				m.mode = modeConfirmDelete
+			}
+			return m, nil
 		}
 	}
 
+	if m.mode == modeBuilder {
+		return m.updateBuilder(msg)
+	}
+
+	return m, m.list.Update(msg)
+}
+
+func (m *mainModel) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		item := m.list.Selected()
+		m.mode = modeList
+		if item == nil {
+			return m, nil
+		}
+		if err := m.store.Delete(item.Key); err != nil {
+			m.setStatus(err.Error(), true)
+			return m, nil
+		}
+		m.refresh("")
+		m.setStatus(fmt.Sprintf("Deleted %s", item.Name), false)
+	case "n", "N", "esc", "q":
+		m.mode = modeList
+	case "ctrl+c":
+		return m, tea.Quit
+	}
 	return m, nil
 }
 
+func (m *mainModel) updateBuilder(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.builder, cmd = m.builder.Update(msg)
+	if !m.builder.finished() {
+		return m, cmd
+	}
+
+	m.mode = modeList
+	if wf := m.builder.saved; wf != nil {
+		m.refresh(wf.Key)
+		m.setStatus(fmt.Sprintf("Created %s", wf.Name), false)
+	}
+	return m, nil
+}
+
+// startEdit writes the workflow to a temp file and opens it in $EDITOR.
+func (m *mainModel) startEdit(wf *workflow.Workflow) (tea.Model, tea.Cmd) {
+	path, err := writeEditFile(wf)
+	if err != nil {
+		m.setStatus(err.Error(), true)
+		return m, nil
+	}
+	m.editKey = wf.Key
+	m.editPath = path
+	return m, openEditor(path)
+}
+
+// finishEdit re-validates the edited file and saves it back to the store.
+// Invalid edits keep the temp file around so the user can fix them.
+func (m *mainModel) finishEdit(err error) (tea.Model, tea.Cmd) {
+	if err != nil {
+		m.discardEdit()
+		m.setStatus(fmt.Sprintf("editor failed: %v", err), true)
+		return m, nil
+	}
+
+	wf, err := readEditFile(m.editPath)
+	if err == nil {
+		err = m.store.Update(m.editKey, wf)
+	}
+	if err != nil {
+		m.editErr = err
+		m.mode = modeEditError
+		return m, nil
+	}
+
+	m.discardEdit()
+	m.refresh(wf.Key)
+	m.setStatus(fmt.Sprintf("Saved %s", wf.Name), false)
+	return m, nil
+}
+
+func (m *mainModel) updateEditError(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "e", "enter":
+		m.mode = modeList
+		return m, openEditor(m.editPath)
+	case "esc", "q":
+		m.discardEdit()
+		m.mode = modeList
+		m.setStatus("Discarded changes", false)
+	case "ctrl+c":
+		m.discardEdit()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *mainModel) discardEdit() {
+	if m.editPath != "" {
+		os.Remove(m.editPath)
+	}
+	m.editKey = ""
+	m.editPath = ""
+	m.editErr = nil
+}
+
 func (m *mainModel) View() string {
+	switch m.mode {
+	case modeBuilder:
+		return m.builder.View()
+	case modeEditError:
+		return lipgloss.JoinVertical(lipgloss.Left,
+			styles.ErrorStyle.Render("The edited workflow is invalid:"),
+			m.editErr.Error(),
+			helpStyle.Render("[e] Edit again  [esc] Discard changes"),
+		)
+	}
 
-	helpText := helpStyle.Render("[n] New  [e] Edit  [d] Delete  [↵] Run  [q] Quit")
-	currentView := m.list.View() + "\n" + helpText
+	helpText := "[n] New  [e] Edit  [d] Delete  [f] Favorite  [s] Sort  [↵] Run  [q] Quit"
+	if m.mode == modeConfirmDelete {
+		if item := m.list.Selected(); item != nil {
+			helpText = styles.ErrorStyle.Render(fmt.Sprintf("Delete %q? [y/n]", item.Name))
+		}
+	} else if m.status != "" {
+		style := styles.SuccessStyle
+		if m.statusErr {
+			style = styles.ErrorStyle
+		}
+		helpText = style.Render(m.status) + "\n" + helpText
+	}
+	helpText = helpStyle.Render(helpText)
+
+	currentView := m.list.View()
+	if width := PreviewWidth(m.width); width > 0 {
+		preview := RenderPreview(m.list.Selected(), width, m.list.Height())
+		listView := lipgloss.NewStyle().Width(m.list.Width()).Render(currentView)
+		currentView = lipgloss.JoinHorizontal(lipgloss.Top, listView, preview)
+	}
+	currentView += "\n" + helpText
 
 	return currentView
 }
@@ -132,17 +324,23 @@ func (m *mainModel) GetAction() (string, *workflow.Workflow) {
 	return m.action, m.selected
 }
 
-func RunMainUI(store *workflow.Store) error {
+// RunMainUI shows the workflow manager and returns the workflow the user
+// chose to run, or nil if they quit.
+func RunMainUI(store *workflow.Store) (*workflow.Workflow, error) {
 	m, err := NewMainModel(store)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err = p.Run()
+	result, err := p.Run()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	action, selected := result.(*mainModel).GetAction()
+	if action != "run" {
+		return nil, nil
+	}
+	return selected, nil
 }
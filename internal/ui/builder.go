@@ -0,0 +1,593 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/kevmul/cmdr/internal/workflow"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// ─── Step builder ─────────────────────────────────────────────────────────────
+//
+// The builder walks through creating a workflow: its name, description and
+// key first, then a list of steps that can be added, edited, reordered,
+// duplicated and deleted, with a live YAML preview alongside.
+
+type builderPage int
+
+const (
+	pageDetails builderPage = iota
+	pageSteps
+	pageStepType
+	pageStepEdit
+)
+
+const (
+	detailName = iota
+	detailDescription
+	detailKey
+)
+
+// builderMinPreviewWidth is the narrowest terminal that still gets the YAML
+// preview next to the form.
+const builderMinPreviewWidth = 90
+
+var stepTypes = []workflow.StepType{
+	workflow.StepTypeCommand,
+	workflow.StepTypeInput,
+	workflow.StepTypeSelect,
+	workflow.StepTypeConfirm,
+	workflow.StepTypeFile,
+	workflow.StepTypeMessage,
+	workflow.StepTypeSet,
+}
+
+type stepFieldInput struct {
+	spec    fieldSpec
+	input   textinput.Model
+	checked bool
+}
+
+type builderModel struct {
+	store *workflow.Store
+	page  builderPage
+
+	details     []textinput.Model
+	detailFocus int
+
+	steps      []workflow.Step
+	cursor     int
+	typeCursor int
+
+	// The step being edited; editing is -1 while adding a new one.
+	editing    int
+	editType   workflow.StepType
+	fields     []stepFieldInput
+	fieldFocus int
+
+	err    error
+	width  int
+	height int
+
+	saved     *workflow.Workflow
+	cancelled bool
+}
+
+func newBuilderModel(store *workflow.Store) builderModel {
+	labels := []string{"Deploy API", "What does this workflow do?", ""}
+	details := make([]textinput.Model, len(labels))
+	for i := range details {
+		t := textinput.New()
+		t.Prompt = "‣ "
+		t.PromptStyle = styles.CursorStyle
+		t.Placeholder = labels[i]
+		t.CharLimit = 256
+		details[i] = t
+	}
+	details[detailName].Focus()
+
+	return builderModel{store: store, details: details}
+}
+
+func (b builderModel) Init() tea.Cmd { return textinput.Blink }
+
+func (b builderModel) finished() bool { return b.saved != nil || b.cancelled }
+
+// workflow assembles the workflow described so far.
+func (b builderModel) workflow() *workflow.Workflow {
+	name := strings.TrimSpace(b.details[detailName].Value())
+	key := strings.TrimSpace(b.details[detailKey].Value())
+	if key == "" {
+		key = workflow.Slugify(name)
+	}
+	return &workflow.Workflow{
+		Key:         key,
+		Name:        name,
+		Description: strings.TrimSpace(b.details[detailDescription].Value()),
+		Steps:       b.steps,
+	}
+}
+
+// checkDetails validates the name and key before moving on to the steps.
+func (b builderModel) checkDetails() error {
+	wf := b.workflow()
+	if wf.Name == "" {
+		return errors.New("name is required")
+	}
+	if workflow.Slugify(wf.Key) != wf.Key {
+		return fmt.Errorf("key must be lowercase letters, numbers and hyphens (try %q)", workflow.Slugify(wf.Key))
+	}
+	if b.store.KeyExists(wf.Key) {
+		return fmt.Errorf("key %q is already in use", wf.Key)
+	}
+	return nil
+}
+
+func (b builderModel) save() (builderModel, tea.Cmd) {
+	if err := b.checkDetails(); err != nil {
+		b.err = err
+		b.page = pageDetails
+		return b, nil
+	}
+
+	wf := b.workflow()
+	if len(wf.Steps) == 0 {
+		b.err = errors.New("add at least one step before saving")
+		return b, nil
+	}
+	if err := wf.Validate(); err != nil {
+		b.err = err
+		return b, nil
+	}
+	if err := b.store.Save(wf); err != nil {
+		b.err = err
+		return b, nil
+	}
+
+	b.saved = wf
+	return b, nil
+}
+
+func (b builderModel) Update(msg tea.Msg) (builderModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width = msg.Width
+		b.height = msg.Height
+		return b, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			b.cancelled = true
+			return b, nil
+		case "ctrl+s":
+			if b.page == pageStepEdit {
+				b = b.applyStep()
+				if b.err != nil {
+					return b, nil
+				}
+			}
+			return b.save()
+		}
+
+		switch b.page {
+		case pageDetails:
+			return b.updateDetails(msg)
+		case pageSteps:
+			return b.updateSteps(msg)
+		case pageStepType:
+			return b.updateStepType(msg)
+		case pageStepEdit:
+			return b.updateStepEdit(msg)
+		}
+	}
+
+	return b.updateFocused(msg)
+}
+
+// updateFocused forwards a message to whichever text input has focus.
+func (b builderModel) updateFocused(msg tea.Msg) (builderModel, tea.Cmd) {
+	var cmd tea.Cmd
+	switch b.page {
+	case pageDetails:
+		b.details[b.detailFocus], cmd = b.details[b.detailFocus].Update(msg)
+		b.details[detailKey].Placeholder = workflow.Slugify(b.details[detailName].Value())
+	case pageStepEdit:
+		if len(b.fields) > 0 && !b.fields[b.fieldFocus].spec.toggle {
+			b.fields[b.fieldFocus].input, cmd = b.fields[b.fieldFocus].input.Update(msg)
+		}
+	}
+	return b, cmd
+}
+
+func (b builderModel) updateDetails(msg tea.KeyMsg) (builderModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		b.cancelled = true
+		return b, nil
+	case "tab", "down":
+		b.detailFocus = focusDetail(b.details, b.detailFocus, b.detailFocus+1)
+		return b, nil
+	case "shift+tab", "up":
+		b.detailFocus = focusDetail(b.details, b.detailFocus, b.detailFocus-1)
+		return b, nil
+	case "enter":
+		if b.detailFocus < len(b.details)-1 {
+			b.detailFocus = focusDetail(b.details, b.detailFocus, b.detailFocus+1)
+			return b, nil
+		}
+		if err := b.checkDetails(); err != nil {
+			b.err = err
+			return b, nil
+		}
+		b.err = nil
+		b.details[b.detailFocus].Blur()
+		b.page = pageSteps
+		return b, nil
+	}
+	return b.updateFocused(msg)
+}
+
+func focusDetail(inputs []textinput.Model, from, to int) int {
+	inputs[from].Blur()
+	to = (to + len(inputs)) % len(inputs)
+	inputs[to].Focus()
+	return to
+}
+
+func (b builderModel) updateSteps(msg tea.KeyMsg) (builderModel, tea.Cmd) {
+	b.err = nil
+	switch msg.String() {
+	case "esc":
+		b.cancelled = true
+	case "up", "k":
+		b.cursor = max(0, b.cursor-1)
+	case "down", "j":
+		b.cursor = max(0, min(len(b.steps)-1, b.cursor+1))
+	case "a", "n":
+		b.page = pageStepType
+		b.typeCursor = 0
+	case "enter", "e":
+		if len(b.steps) > 0 {
+			return b.startStepEdit(b.cursor, b.steps[b.cursor])
+		}
+		b.page = pageStepType
+	case "K", "shift+up":
+		if b.cursor > 0 {
+			b.steps[b.cursor-1], b.steps[b.cursor] = b.steps[b.cursor], b.steps[b.cursor-1]
+			b.cursor--
+		}
+	case "J", "shift+down":
+		if b.cursor < len(b.steps)-1 {
+			b.steps[b.cursor+1], b.steps[b.cursor] = b.steps[b.cursor], b.steps[b.cursor+1]
+			b.cursor++
+		}
+	case "y", "c":
+		if len(b.steps) > 0 {
+			dup := b.steps[b.cursor]
+			b.steps = append(b.steps[:b.cursor+1], append([]workflow.Step{dup}, b.steps[b.cursor+1:]...)...)
+			b.cursor++
+		}
+	case "d", "x":
+		if len(b.steps) > 0 {
+			b.steps = append(b.steps[:b.cursor], b.steps[b.cursor+1:]...)
+			b.cursor = max(0, min(b.cursor, len(b.steps)-1))
+		}
+	case "tab", "shift+tab":
+		b.page = pageDetails
+		b.details[b.detailFocus].Focus()
+	}
+	return b, nil
+}
+
+func (b builderModel) updateStepType(msg tea.KeyMsg) (builderModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		b.page = pageSteps
+	case "up", "k":
+		b.typeCursor = max(0, b.typeCursor-1)
+	case "down", "j":
+		b.typeCursor = min(len(stepTypes)-1, b.typeCursor+1)
+	case "enter":
+		return b.startStepEdit(-1, workflow.Step{Type: stepTypes[b.typeCursor]})
+	}
+	return b, nil
+}
+
+// startStepEdit opens the field editor for a step. index is the position
+// of an existing step, or -1 to append a new one.
+func (b builderModel) startStepEdit(index int, step workflow.Step) (builderModel, tea.Cmd) {
+	specs := stepFields[step.Type]
+	fields := make([]stepFieldInput, len(specs))
+	for i, spec := range specs {
+		t := textinput.New()
+		t.Prompt = "‣ "
+		t.PromptStyle = styles.CursorStyle
+		t.Placeholder = spec.placeholder
+		t.SetValue(spec.get(&step))
+		fields[i] = stepFieldInput{spec: spec, input: t, checked: spec.get(&step) != ""}
+	}
+
+	b.editing = index
+	b.editType = step.Type
+	b.fields = fields
+	b.fieldFocus = 0
+	b.err = nil
+	b.page = pageStepEdit
+	b.focusField(0)
+	return b, textinput.Blink
+}
+
+func (b *builderModel) focusField(i int) {
+	if len(b.fields) == 0 {
+		return
+	}
+	b.fields[b.fieldFocus].input.Blur()
+	b.fieldFocus = (i + len(b.fields)) % len(b.fields)
+	if !b.fields[b.fieldFocus].spec.toggle {
+		b.fields[b.fieldFocus].input.Focus()
+	}
+}
+
+// editedStep builds a step from the current field values.
+func (b builderModel) editedStep() (workflow.Step, error) {
+	step := workflow.Step{Type: b.editType}
+	if b.editing >= 0 {
+		step = b.steps[b.editing]
+	}
+
+	for _, f := range b.fields {
+		value := strings.TrimSpace(f.input.Value())
+		if f.spec.toggle {
+			value = ""
+			if f.checked {
+				value = "true"
+			}
+		}
+		if err := f.spec.set(&step, value); err != nil {
+			return step, fmt.Errorf("%s: %w", strings.ToLower(f.spec.label), err)
+		}
+	}
+	return step, nil
+}
+
+// applyStep validates the edited step and puts it into the step list.
+func (b builderModel) applyStep() builderModel {
+	step, err := b.editedStep()
+	if err == nil {
+		err = step.Validate()
+	}
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	if b.editing >= 0 {
+		b.steps[b.editing] = step
+	} else {
+		b.steps = append(b.steps, step)
+		b.cursor = len(b.steps) - 1
+	}
+	b.err = nil
+	b.page = pageSteps
+	return b
+}
+
+func (b builderModel) updateStepEdit(msg tea.KeyMsg) (builderModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		b.err = nil
+		b.page = pageSteps
+		return b, nil
+	case "tab", "down":
+		b.focusField(b.fieldFocus + 1)
+		return b, nil
+	case "shift+tab", "up":
+		b.focusField(b.fieldFocus - 1)
+		return b, nil
+	case " ":
+		if b.fields[b.fieldFocus].spec.toggle {
+			b.fields[b.fieldFocus].checked = !b.fields[b.fieldFocus].checked
+			return b, nil
+		}
+	case "enter":
+		if b.fieldFocus < len(b.fields)-1 {
+			b.focusField(b.fieldFocus + 1)
+			return b, nil
+		}
+		return b.applyStep(), nil
+	}
+	return b.updateFocused(msg)
+}
+
+// ─── View ─────────────────────────────────────────────────────────────────────
+
+func (b builderModel) View() string {
+	var body string
+	switch b.page {
+	case pageDetails:
+		body = b.detailsView()
+	case pageSteps:
+		body = b.stepsView()
+	case pageStepType:
+		body = b.stepTypeView()
+	case pageStepEdit:
+		body = b.stepEditView()
+	}
+
+	if b.err != nil {
+		body += "\n" + styles.ErrorStyle.Render(b.err.Error())
+	}
+	body = lipgloss.JoinVertical(lipgloss.Left,
+		styles.TitleStyle.Render("New workflow"),
+		"",
+		body,
+		helpStyle.Render(b.helpText()),
+	)
+
+	if b.width < builderMinPreviewWidth {
+		return body
+	}
+
+	formWidth := b.width / 2
+	preview := b.previewView(b.width-formWidth-4, max(5, b.height-2))
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(formWidth).Render(body),
+		preview,
+	)
+}
+
+func (b builderModel) helpText() string {
+	switch b.page {
+	case pageDetails:
+		return "[tab] Next field  [↵] Continue to steps  [ctrl+s] Save  [esc] Cancel"
+	case pageSteps:
+		return "[a] Add  [↵] Edit  [K/J] Move  [y] Duplicate  [d] Delete\n[tab] Details  [ctrl+s] Save  [esc] Cancel"
+	case pageStepType:
+		return "[↵] Choose  [esc] Back"
+	default:
+		return "[tab] Next field  [space] Toggle  [↵] Next / Done  [esc] Discard"
+	}
+}
+
+func (b builderModel) detailsView() string {
+	labels := []string{"Name", "Description", "Key"}
+	var rows []string
+	for i, input := range b.details {
+		style := styles.BlurredInputStyle
+		if i == b.detailFocus {
+			style = styles.FocusedInputStyle
+		}
+		rows = append(rows, styles.MutedTextStyle.Render(labels[i]), style.Width(50).Render(input.View()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (b builderModel) stepsView() string {
+	wf := b.workflow()
+	header := fmt.Sprintf("%s %s", wf.Name, styles.MutedTextStyle.Render("("+wf.Key+")"))
+	if len(b.steps) == 0 {
+		return header + "\n\n" + styles.MutedTextStyle.Render("No steps yet — press [a] to add one.")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header + "\n\n")
+	for i, step := range b.steps {
+		line := fmt.Sprintf("%d. %s %s", i+1, stepIcon(step.Type), step.Label())
+		line = lipgloss.NewStyle().MaxWidth(max(20, b.width/2-4)).Render(line)
+		if i == b.cursor {
+			fmt.Fprintf(&sb, "%s %s\n", styles.CursorStyle.Render("▶"), styles.SelectedItemStyle.Render(line))
+		} else {
+			fmt.Fprintf(&sb, "  %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+func (b builderModel) stepTypeView() string {
+	var sb strings.Builder
+	sb.WriteString("Add a step:\n\n")
+	for i, t := range stepTypes {
+		line := fmt.Sprintf("%s %s", stepIcon(t), t)
+		if i == b.typeCursor {
+			fmt.Fprintf(&sb, "%s %s\n", styles.CursorStyle.Render("▶"), styles.SelectedItemStyle.Render(line))
+		} else {
+			fmt.Fprintf(&sb, "  %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+func (b builderModel) stepEditView() string {
+	title := fmt.Sprintf("%s %s step", stepIcon(b.editType), b.editType)
+	rows := []string{styles.InfoStyle.Render(title), ""}
+	for i, f := range b.fields {
+		focused := i == b.fieldFocus
+		if f.spec.toggle {
+			box := "[ ]"
+			if f.checked {
+				box = "[x]"
+			}
+			line := fmt.Sprintf("%s %s", box, f.spec.label)
+			if focused {
+				line = styles.CursorStyle.Render("▶ ") + styles.SelectedItemStyle.Render(line)
+			} else {
+				line = "  " + line
+			}
+			rows = append(rows, line)
+			continue
+		}
+
+		style := styles.BlurredInputStyle
+		if focused {
+			style = styles.FocusedInputStyle
+		}
+		rows = append(rows, styles.MutedTextStyle.Render(f.spec.label), style.Width(50).Render(f.input.View()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// previewView renders the workflow as it would be saved, including any
+// step currently being edited.
+func (b builderModel) previewView(width, height int) string {
+	wf := b.workflow()
+	if b.page == pageStepEdit {
+		if step, err := b.editedStep(); err == nil {
+			steps := append([]workflow.Step(nil), wf.Steps...)
+			if b.editing >= 0 {
+				steps[b.editing] = step
+			} else {
+				steps = append(steps, step)
+			}
+			wf.Steps = steps
+		}
+	}
+
+	data, err := yaml.Marshal(wf)
+	content := string(data)
+	if err != nil {
+		content = err.Error()
+	}
+
+	return styles.CommandStyle.
+		Width(width).
+		MaxHeight(height).
+		Render(styles.MutedTextStyle.Render("YAML preview") + "\n\n" + strings.TrimRight(content, "\n"))
+}
+
+// ─── Standalone program ───────────────────────────────────────────────────────
+
+// builderProgram hosts the builder as its own program for `cmdr new`.
+type builderProgram struct {
+	builder builderModel
+}
+
+func (p builderProgram) Init() tea.Cmd { return p.builder.Init() }
+
+func (p builderProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	p.builder, cmd = p.builder.Update(msg)
+	if p.builder.finished() {
+		return p, tea.Quit
+	}
+	return p, cmd
+}
+
+func (p builderProgram) View() string { return p.builder.View() }
+
+// RunBuilder walks the user through creating a workflow and saves it to
+// the store. It returns the saved workflow, or nil if the user cancelled.
+func RunBuilder(store *workflow.Store) (*workflow.Workflow, error) {
+	p := tea.NewProgram(builderProgram{builder: newBuilderModel(store)}, tea.WithAltScreen())
+	result, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return result.(builderProgram).builder.saved, nil
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+type editorFinishedMsg struct {
+	err error
+}
+
+// editorCommand builds the command for the user's preferred editor,
+// honouring $VISUAL then $EDITOR and falling back to vi. The variables may
+// include arguments, e.g. "code --wait".
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	parts := strings.Fields(editor)
+	return exec.Command(parts[0], append(parts[1:], path)...)
+}
+
+// openEditor suspends the UI and opens path in the user's editor.
+func openEditor(path string) tea.Cmd {
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return editorFinishedMsg{err: err}
+	})
+}
+
+// writeEditFile writes a single workflow to a temporary YAML file so it can
+// be edited without exposing the rest of the store.
+func writeEditFile(wf *workflow.Workflow) (string, error) {
+	data, err := yaml.Marshal(wf)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("cmdr-%s-*.yaml", wf.Key))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// readEditFile parses and validates a workflow written by writeEditFile.
+func readEditFile(path string) (*workflow.Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var wf workflow.Workflow
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
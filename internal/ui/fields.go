@@ -0,0 +1,321 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/workflow"
+)
+
+// fieldSpec describes one editable field of a step in the builder. Toggle
+// fields are booleans and round-trip through get/set as "true" or "".
+type fieldSpec struct {
+	label       string
+	placeholder string
+	toggle      bool
+	get         func(s *workflow.Step) string
+	set         func(s *workflow.Step, value string) error
+}
+
+func textField(label, placeholder string, ptr func(s *workflow.Step) *string) fieldSpec {
+	return fieldSpec{
+		label:       label,
+		placeholder: placeholder,
+		get:         func(s *workflow.Step) string { return *ptr(s) },
+		set: func(s *workflow.Step, value string) error {
+			*ptr(s) = value
+			return nil
+		},
+	}
+}
+
+func toggleField(label string, ptr func(s *workflow.Step) *bool) fieldSpec {
+	return fieldSpec{
+		label:  label,
+		toggle: true,
+		get: func(s *workflow.Step) string {
+			if *ptr(s) {
+				return "true"
+			}
+			return ""
+		},
+		set: func(s *workflow.Step, value string) error {
+			*ptr(s) = value != ""
+			return nil
+		},
+	}
+}
+
+var (
+	promptField   = textField("Prompt", "What should the user see?", func(s *workflow.Step) *string { return &s.Prompt })
+	helpTextField = textField("Help text", "Optional hint shown under the prompt", func(s *workflow.Step) *string { return &s.HelpText })
+	variableField = textField("Variable", "name used as {{variable}} later", func(s *workflow.Step) *string { return &s.Variable })
+
+	optionsField = fieldSpec{
+		label:       "Options",
+		placeholder: "Staging=staging, Production=prod",
+		get:         func(s *workflow.Step) string { return formatOptions(s.Options) },
+		set: func(s *workflow.Step, value string) error {
+			s.Options = parseOptions(value)
+			return nil
+		},
+	}
+
+	filtersField = fieldSpec{
+		label:       "Filters",
+		placeholder: "*.sql, *.yaml",
+		get:         func(s *workflow.Step) string { return strings.Join(s.Filters, ", ") },
+		set: func(s *workflow.Step, value string) error {
+			s.Filters = splitList(value)
+			return nil
+		},
+	}
+
+	envField = fieldSpec{
+		label:       "Env",
+		placeholder: "NODE_ENV=production, PORT={{port}}",
+		get:         func(s *workflow.Step) string { return formatEnv(s.Env) },
+		set: func(s *workflow.Step, value string) error {
+			env, err := parseEnv(value)
+			if err != nil {
+				return err
+			}
+			s.Env = env
+			return nil
+		},
+	}
+
+	valuesField = fieldSpec{
+		label:       "Values",
+		placeholder: "image={{registry}}/{{service}}; next={{add build 1}}",
+		get:         func(s *workflow.Step) string { return formatValues(s.Values) },
+		set: func(s *workflow.Step, value string) error {
+			values, err := parseValues(value)
+			if err != nil {
+				return err
+			}
+			s.Values = values
+			return nil
+		},
+	}
+
+	shellField = fieldSpec{
+		label:       "Shell",
+		placeholder: "sh, bash, python3, node or a full command line",
+		get:         func(s *workflow.Step) string { return s.Shell.String() },
+		set: func(s *workflow.Step, value string) error {
+			s.Shell = workflow.Shell(strings.Fields(value))
+			return nil
+		},
+	}
+
+	strictField = fieldSpec{
+		label:  "Strict mode",
+		toggle: true,
+		get: func(s *workflow.Step) string {
+			if s.Strict != nil && *s.Strict {
+				return "true"
+			}
+			return ""
+		},
+		set: func(s *workflow.Step, value string) error {
+			if value == "" {
+				s.Strict = nil
+				return nil
+			}
+			strict := true
+			s.Strict = &strict
+			return nil
+		},
+	}
+
+	conditionField = fieldSpec{
+		label:       "Condition",
+		placeholder: "variable equals value (optional)",
+		get:         func(s *workflow.Step) string { return formatCondition(s.Condition) },
+		set: func(s *workflow.Step, value string) error {
+			c, err := parseCondition(value)
+			if err != nil {
+				return err
+			}
+			s.Condition = c
+			return nil
+		},
+	}
+)
+
+// stepFields lists the fields the builder offers for each step type.
+var stepFields = map[workflow.StepType][]fieldSpec{
+	workflow.StepTypeMessage: {
+		promptField,
+		textField("Variant", "info, success, warning or error", func(s *workflow.Step) *string { return &s.Variant }),
+		conditionField,
+	},
+	workflow.StepTypeInput: {
+		promptField,
+		helpTextField,
+		variableField,
+		conditionField,
+	},
+	workflow.StepTypeSelect: {
+		promptField,
+		helpTextField,
+		variableField,
+		optionsField,
+		textField("Default", "Preselected option value", func(s *workflow.Step) *string { return &s.Default }),
+		conditionField,
+	},
+	workflow.StepTypeConfirm: {
+		promptField,
+		variableField,
+		conditionField,
+	},
+	workflow.StepTypeCommand: {
+		textField("Command", "echo \"Hello {{name}}\"", func(s *workflow.Step) *string { return &s.Command }),
+		textField("Description", "Shown instead of the command while running", func(s *workflow.Step) *string { return &s.Description }),
+		toggleField("Capture output", func(s *workflow.Step) *bool { return &s.CaptureOutput }),
+		textField("Output variable", "Stores the captured stdout", func(s *workflow.Step) *string { return &s.OutputVariable }),
+		toggleField("Capture env", func(s *workflow.Step) *bool { return &s.CaptureEnv }),
+		toggleField("Ignore errors", func(s *workflow.Step) *bool { return &s.IgnoreError }),
+		toggleField("Interactive", func(s *workflow.Step) *bool { return &s.Interactive }),
+		shellField,
+		strictField,
+		textField("Directory", "Working directory, e.g. services/api", func(s *workflow.Step) *string { return &s.Dir }),
+		envField,
+		conditionField,
+	},
+	workflow.StepTypeFile: {
+		promptField,
+		helpTextField,
+		variableField,
+		textField("Root", "Directory to browse, defaults to .", func(s *workflow.Step) *string { return &s.Root }),
+		filtersField,
+		toggleField("Directories only", func(s *workflow.Step) *bool { return &s.DirOnly }),
+		toggleField("Multiple", func(s *workflow.Step) *bool { return &s.Multiple }),
+		toggleField("Show hidden", func(s *workflow.Step) *bool { return &s.ShowHidden }),
+		toggleField("Relative path", func(s *workflow.Step) *bool { return &s.RelativePath }),
+		conditionField,
+	},
+	workflow.StepTypeSet: {
+		valuesField,
+		textField("Description", "Shown instead of the variable names while running", func(s *workflow.Step) *string { return &s.Description }),
+		conditionField,
+	},
+}
+
+// formatOptions renders options as "Text=value" pairs, dropping the value
+// when it matches the text.
+func formatOptions(options []workflow.SelectOption) string {
+	parts := make([]string, len(options))
+	for i, opt := range options {
+		if opt.Value == "" || opt.Value == opt.Text {
+			parts[i] = opt.Text
+		} else {
+			parts[i] = opt.Text + "=" + opt.Value
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func parseOptions(value string) []workflow.SelectOption {
+	var options []workflow.SelectOption
+	for _, part := range splitList(value) {
+		text, val, found := strings.Cut(part, "=")
+		text = strings.TrimSpace(text)
+		if !found {
+			val = text
+		}
+		options = append(options, workflow.SelectOption{Text: text, Value: strings.TrimSpace(val)})
+	}
+	return options
+}
+
+// formatEnv renders env vars as "KEY=value" pairs in key order.
+func formatEnv(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + env[k]
+	}
+	return strings.Join(parts, ", ")
+}
+
+func parseEnv(value string) (map[string]string, error) {
+	parts := splitList(value)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(parts))
+	for _, part := range parts {
+		k, v, found := strings.Cut(part, "=")
+		if !found || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("env must look like \"KEY=value, KEY2=value\"")
+		}
+		env[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return env, nil
+}
+
+// formatValues renders a set step's values as "name=value" pairs in
+// order. They're separated by semicolons, as values often contain commas.
+func formatValues(values workflow.Assignments) string {
+	parts := make([]string, len(values))
+	for i, a := range values {
+		parts[i] = a.Name + "=" + a.Value
+	}
+	return strings.Join(parts, "; ")
+}
+
+func parseValues(value string) (workflow.Assignments, error) {
+	var values workflow.Assignments
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, v, found := strings.Cut(part, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("values must look like \"name=value; name2=value\"")
+		}
+		values = append(values, workflow.Assignment{Name: strings.TrimSpace(name), Value: strings.TrimSpace(v)})
+	}
+	return values, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+func formatCondition(c *workflow.Condition) string {
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", c.Variable, c.Operator, c.Value))
+}
+
+// parseCondition reads "variable operator [value]", e.g. "env equals prod".
+func parseCondition(value string) (*workflow.Condition, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("condition must look like \"variable equals value\"")
+	}
+	return &workflow.Condition{
+		Variable: fields[0],
+		Operator: fields[1],
+		Value:    strings.Join(fields[2:], " "),
+	}, nil
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/kevmul/cmdr/internal/workflow"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─── Workflow picker ──────────────────────────────────────────────────────────
+//
+// The picker is a checklist of workflows about to be saved, such as ones
+// generated by `cmdr import`, with the highlighted one previewed alongside.
+
+type pickerKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+	All    key.Binding
+	Done   key.Binding
+	Cancel key.Binding
+}
+
+var pickerKeys = pickerKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("k, up", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("j, down", "move down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
+	All: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle all"),
+	),
+	Done: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "save selected"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc", "q", "ctrl+c"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+type pickerModel struct {
+	title     string
+	workflows []workflow.Workflow
+	notes     []string // shown next to each workflow, e.g. "replaces existing"
+	checked   []bool
+	cursor    int
+	offset    int
+	width     int
+	height    int
+	done      bool
+	cancelled bool
+}
+
+func (m pickerModel) Init() tea.Cmd { return nil }
+
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, pickerKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, pickerKeys.Down):
+			if m.cursor < len(m.workflows)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, pickerKeys.Toggle):
+			if len(m.checked) > 0 {
+				m.checked[m.cursor] = !m.checked[m.cursor]
+			}
+		case key.Matches(msg, pickerKeys.All):
+			all := true
+			for _, c := range m.checked {
+				all = all && c
+			}
+			for i := range m.checked {
+				m.checked[i] = !all
+			}
+		case key.Matches(msg, pickerKeys.Done):
+			m.done = true
+			return m, tea.Quit
+		case key.Matches(msg, pickerKeys.Cancel):
+			m.cancelled = true
+			return m, tea.Quit
+		}
+	}
+
+	// Keep the cursor in view.
+	rows := m.rows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	return m, nil
+}
+
+// rows is how many workflows fit in the list.
+func (m pickerModel) rows() int {
+	return max(1, m.height-6)
+}
+
+func (m pickerModel) View() string {
+	listWidth := m.width - PreviewWidth(m.width)
+
+	selected := 0
+	for _, c := range m.checked {
+		if c {
+			selected++
+		}
+	}
+
+	lines := []string{
+		styles.TitleStyle.Render(m.title),
+		styles.MutedTextStyle.Render(fmt.Sprintf("%d of %d selected", selected, len(m.workflows))),
+		"",
+	}
+	end := min(len(m.workflows), m.offset+m.rows())
+	for i := m.offset; i < end; i++ {
+		wf := m.workflows[i]
+		box := "[ ]"
+		if m.checked[i] {
+			box = styles.SuccessStyle.Render("[✓]")
+		}
+		line := fmt.Sprintf("%s %s %s", box, wf.Name, styles.MutedTextStyle.Render("("+wf.Key+")"))
+		if m.notes[i] != "" {
+			line += " " + styles.ErrorStyle.Render(m.notes[i])
+		}
+		if i == m.cursor {
+			line = styles.CursorStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(max(1, listWidth)).Render(line))
+	}
+
+	help := "[space] Toggle  [a] Toggle all  [↵] Save selected  [esc] Cancel"
+	lines = append(lines, "", helpStyle.Render(help))
+	list := strings.Join(lines, "\n")
+
+	previewWidth := PreviewWidth(m.width)
+	if previewWidth == 0 || len(m.workflows) == 0 {
+		return list
+	}
+	wf := m.workflows[m.cursor]
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listWidth).Render(list),
+		RenderPreview(&wf, previewWidth, max(5, m.height-1)),
+	)
+}
+
+// RunPicker shows workflows as a checklist and returns the ones the user
+// chose, or nil if they cancelled. Workflows are checked to begin with
+// unless they have a note, which explains why not.
+func RunPicker(title string, workflows []workflow.Workflow, notes []string) ([]workflow.Workflow, error) {
+	m := pickerModel{
+		title:     title,
+		workflows: workflows,
+		notes:     notes,
+		checked:   make([]bool, len(workflows)),
+	}
+	for i := range m.checked {
+		m.checked[i] = notes[i] == ""
+	}
+
+	result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, err
+	}
+
+	final := result.(pickerModel)
+	if final.cancelled {
+		return nil, nil
+	}
+	picked := []workflow.Workflow{}
+	for i, wf := range final.workflows {
+		if final.checked[i] {
+			picked = append(picked, wf)
+		}
+	}
+	return picked, nil
+}
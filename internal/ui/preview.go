@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/kevmul/cmdr/internal/workflow"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─── Step preview ─────────────────────────────────────────────────────────────
+
+const (
+	// previewMinTotalWidth is the narrowest terminal that still gets a
+	// preview pane next to the workflow list.
+	previewMinTotalWidth = 80
+	previewMaxWidth      = 70
+)
+
+// stepIcon is a one-character marker for each step type.
+func stepIcon(t workflow.StepType) string {
+	switch t {
+	case workflow.StepTypeCommand:
+		return "$"
+	case workflow.StepTypeInput:
+		return "✎"
+	case workflow.StepTypeSelect:
+		return "≡"
+	case workflow.StepTypeConfirm:
+		return "?"
+	case workflow.StepTypeFile:
+		return "▤"
+	case workflow.StepTypeMessage:
+		return "✉"
+	case workflow.StepTypeSet:
+		return "="
+	default:
+		return "•"
+	}
+}
+
+// PreviewWidth returns how many columns of a list view of the given width
+// go to the step preview. It is zero when the terminal is too narrow, in
+// which case the list should take the full width.
+func PreviewWidth(total int) int {
+	if total < previewMinTotalWidth {
+		return 0
+	}
+	return min(total*2/5, previewMaxWidth)
+}
+
+// RenderPreview renders a workflow's steps for the preview pane: each step's
+// type icon, its prompt or command, any condition, and the variables it
+// produces. Steps that don't fit in height are summarised on the last line.
+func RenderPreview(wf *workflow.Workflow, width, height int) string {
+	if wf == nil || width <= 0 || height <= 0 {
+		return ""
+	}
+
+	inner := max(1, width-4)
+	text := lipgloss.NewStyle().MaxWidth(inner)
+
+	lines := []string{styles.TitleStyle.Render(text.Render(wf.Name)), ""}
+	if len(wf.Steps) == 0 {
+		lines = append(lines, styles.MutedTextStyle.Render("No steps"))
+	}
+
+	for i, step := range wf.Steps {
+		block := []string{text.Render(fmt.Sprintf("%d. %s %s", i+1, styles.InfoStyle.Render(stepIcon(step.Type)), stepSummary(step)))}
+		if c := step.Condition; c != nil {
+			block = append(block, text.Render(styles.MutedTextStyle.Render("   if "+formatCondition(c))))
+		}
+		if vars := stepVariables(step); len(vars) > 0 {
+			block = append(block, text.Render(styles.SuccessStyle.Render("   → "+strings.Join(vars, ", "))))
+		}
+
+		// Leave room for the border and a "more" line.
+		if len(lines)+len(block) > height-3 {
+			more := fmt.Sprintf("… %d more step(s)", len(wf.Steps)-i)
+			lines = append(lines, styles.MutedTextStyle.Render(more))
+			break
+		}
+		lines = append(lines, block...)
+	}
+
+	return styles.BlurredInputStyle.
+		Width(width - 2).
+		Height(max(1, height-2)).
+		Render(strings.Join(lines, "\n"))
+}
+
+// stepSummary is the single most useful line about a step: the command it
+// runs or the prompt it shows.
+func stepSummary(step workflow.Step) string {
+	switch {
+	case step.Command != "":
+		return step.Command
+	case step.Script != "":
+		return step.Label()
+	case step.Prompt != "":
+		return step.Prompt
+	default:
+		return step.Label()
+	}
+}
+
+// stepVariables lists the template variables a step makes available to the
+// steps after it.
+func stepVariables(step workflow.Step) []string {
+	var vars []string
+	if step.Variable != "" {
+		vars = append(vars, "{{"+step.Variable+"}}")
+	}
+	if step.OutputVariable != "" {
+		vars = append(vars, "{{"+step.OutputVariable+"}}")
+	}
+	for _, a := range step.Values {
+		vars = append(vars, "{{"+a.Name+"}}")
+	}
+	if step.CaptureEnv {
+		vars = append(vars, "env vars")
+	}
+	return vars
+}
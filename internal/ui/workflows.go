@@ -0,0 +1,445 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/kevmul/cmdr/internal/workflow"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ─── Workflow list ────────────────────────────────────────────────────────────
+
+const favoritesGroup = "★ Favorites"
+
+type sortOrder int
+
+const (
+	sortFile sortOrder = iota
+	sortRecent
+	sortFrequent
+)
+
+func (o sortOrder) String() string {
+	switch o {
+	case sortRecent:
+		return "recent"
+	case sortFrequent:
+		return "frequent"
+	default:
+		return "file order"
+	}
+}
+
+type listKeyMap struct {
+	Favorite key.Binding
+	Sort     key.Binding
+	Toggle   key.Binding
+}
+
+var listKeys = listKeyMap{
+	Favorite: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "favorite"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "sort"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("↵", "expand/collapse group"),
+	),
+}
+
+type workflowItem struct {
+	workflow.Workflow
+	favorite bool
+
+	// match replaces the description while filtering, showing the field
+	// that matched the query.
+	match string
+}
+
+func (i workflowItem) Title() string {
+	title := i.Name
+	if i.favorite {
+		title = "★ " + title
+	}
+	key := styles.MutedTextStyle.Render(fmt.Sprintf("(%s)", i.Key))
+	return fmt.Sprintf("%s %s", title, key)
+}
+
+func (i workflowItem) Description() string {
+	if i.match != "" {
+		return i.match
+	}
+	if len(i.Tags) == 0 {
+		return i.Workflow.Description
+	}
+	tags := "#" + strings.Join(i.Tags, " #")
+	if i.Workflow.Description == "" {
+		return tags
+	}
+	return i.Workflow.Description + "  " + tags
+}
+
+// FilterValue is the name. The list's filter, workflowFilter, finds the
+// item itself and searches all of its fields.
+func (i workflowItem) FilterValue() string { return i.Name }
+
+// workflowDelegate shows what matched in place of the description while a
+// filter is active, so a hit in a step command is visible in the list.
+type workflowDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d workflowDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if wi, ok := item.(workflowItem); ok && m.FilterState() != list.Unfiltered && m.FilterValue() != "" {
+		if results := workflow.Search([]workflow.Workflow{wi.Workflow}, m.FilterValue()); len(results) > 0 {
+			wi.match = matchContext(results[0])
+			item = wi
+		}
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// matchContext describes the first match outside the name, which the title
+// already highlights.
+func matchContext(result workflow.SearchResult) string {
+	const lead = 20
+	for _, match := range result.Matches {
+		if match.Field == "name" {
+			continue
+		}
+		line := match.Line()
+		// Keep the match in view when it sits far along a long line.
+		term := strings.TrimPrefix(match.Term, "#")
+		lower := strings.ToLower(line)
+		if pos := strings.Index(lower, term); pos > lead && len(lower) == len(line) {
+			line = "…" + line[pos-lead:]
+		}
+		return styles.MutedTextStyle.Render(match.Where()+":") + " " + HighlightTerm(line, term)
+	}
+	return ""
+}
+
+// HighlightTerm emphasises each case-insensitive occurrence of term in s.
+func HighlightTerm(s, term string) string {
+	lower := strings.ToLower(s)
+	// Offsets into lower only line up with s when lowering kept the length.
+	if term == "" || len(lower) != len(s) {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		pos := strings.Index(lower, term)
+		if pos == -1 {
+			sb.WriteString(s)
+			return sb.String()
+		}
+		sb.WriteString(s[:pos])
+		sb.WriteString(styles.CursorStyle.Render(s[pos : pos+len(term)]))
+		s, lower = s[pos+len(term):], lower[pos+len(term):]
+	}
+}
+
+type groupItem struct {
+	name      string
+	count     int
+	collapsed bool
+}
+
+func (g groupItem) Title() string {
+	arrow := "▾"
+	if g.collapsed {
+		arrow = "▸"
+	}
+	return styles.InfoStyle.Render(fmt.Sprintf("%s %s (%d)", arrow, g.name, g.count))
+}
+func (g groupItem) Description() string { return "" }
+func (g groupItem) FilterValue() string { return "" }
+
+// WorkflowList is the list of workflows shared by the main UI and
+// `cmdr list`. Favorites are pinned to the top, the rest are arranged in
+// collapsible groups, and the list can be ordered by file position, most
+// recent run or run count.
+type WorkflowList struct {
+	list      list.Model
+	store     *workflow.Store
+	workflows []workflow.Workflow
+	state     *workflow.State
+	collapsed map[string]bool
+	order     sortOrder
+
+	// expanded ignores collapsed groups while the user is filtering, so
+	// nothing is hidden from a search.
+	expanded bool
+}
+
+// NewWorkflowList loads the workflows and the user's state from the store.
+func NewWorkflowList(store *workflow.Store) (WorkflowList, error) {
+	d := list.NewDefaultDelegate()
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(styles.Primary).BorderLeftForeground(styles.Primary)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(styles.Tertiary).BorderLeftForeground(styles.Primary)
+
+	l := list.New(nil, workflowDelegate{d}, 0, 0)
+	l.Title = "Command Runner"
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("workflow", "workflows")
+
+	wl := WorkflowList{
+		list:      l,
+		store:     store,
+		collapsed: make(map[string]bool),
+	}
+	if err := wl.Reload(); err != nil {
+		return wl, err
+	}
+	return wl, nil
+}
+
+// Reload re-reads workflows and state from the store, keeping the current
+// selection where possible.
+func (l *WorkflowList) Reload() error {
+	workflows, err := l.store.List()
+	if err != nil {
+		return err
+	}
+	state, err := l.store.LoadState()
+	if err != nil {
+		return err
+	}
+
+	l.workflows = workflows
+	l.state = state
+
+	selected := ""
+	if wf := l.Selected(); wf != nil {
+		selected = wf.Key
+	}
+	l.rebuild()
+	l.Select(selected)
+	return nil
+}
+
+// Len is the number of workflows, not counting group headers.
+func (l WorkflowList) Len() int { return len(l.workflows) }
+
+// Rows is the number of list entries currently shown, including group
+// headers.
+func (l WorkflowList) Rows() int { return len(l.list.Items()) }
+
+// Select moves the cursor to the workflow with the given key.
+func (l *WorkflowList) Select(key string) {
+	for i, item := range l.list.Items() {
+		if wi, ok := item.(workflowItem); ok && wi.Key == key {
+			l.list.Select(i)
+			return
+		}
+	}
+}
+
+// Selected returns the highlighted workflow, or nil when the cursor is on a
+// group header or the list is empty.
+func (l WorkflowList) Selected() *workflow.Workflow {
+	if item, ok := l.list.SelectedItem().(workflowItem); ok {
+		return &item.Workflow
+	}
+	return nil
+}
+
+func (l *WorkflowList) SetSize(width, height int) { l.list.SetSize(width, height) }
+func (l WorkflowList) Width() int                 { return l.list.Width() }
+func (l WorkflowList) Height() int                { return l.list.Height() }
+
+// Filtering reports whether the user is typing a filter, in which case
+// every key belongs to the list.
+func (l WorkflowList) Filtering() bool {
+	return l.list.FilterState() == list.Filtering
+}
+
+// ResetFilter clears any active filter.
+func (l *WorkflowList) ResetFilter() {
+	l.list.ResetFilter()
+	l.expanded = false
+	l.rebuild()
+}
+
+// sorted returns the workflows in the current sort order. Ties keep file
+// order.
+func (l WorkflowList) sorted() []workflow.Workflow {
+	workflows := append([]workflow.Workflow(nil), l.workflows...)
+	history := l.state.History
+
+	switch l.order {
+	case sortRecent:
+		sort.SliceStable(workflows, func(i, j int) bool {
+			return history[workflows[i].Key].LastRun.After(history[workflows[j].Key].LastRun)
+		})
+	case sortFrequent:
+		sort.SliceStable(workflows, func(i, j int) bool {
+			return history[workflows[i].Key].Count > history[workflows[j].Key].Count
+		})
+	}
+	return workflows
+}
+
+// rebuild lays the workflows out as list items: favorites first, then each
+// group in order of first appearance, then anything ungrouped. Headers are
+// only shown when there is more than one section.
+func (l *WorkflowList) rebuild() {
+	var sections []string
+	members := make(map[string][]list.Item)
+
+	for _, wf := range l.sorted() {
+		favorite := l.state.IsFavorite(wf.Key)
+		section := wf.Group
+		if favorite {
+			section = favoritesGroup
+		}
+		if _, seen := members[section]; !seen {
+			sections = append(sections, section)
+		}
+		members[section] = append(members[section], workflowItem{Workflow: wf, favorite: favorite})
+	}
+
+	// Favorites lead and ungrouped workflows trail.
+	sort.SliceStable(sections, func(i, j int) bool {
+		rank := func(s string) int {
+			switch s {
+			case favoritesGroup:
+				return 0
+			case "":
+				return 2
+			default:
+				return 1
+			}
+		}
+		return rank(sections[i]) < rank(sections[j])
+	})
+
+	showHeaders := len(sections) > 1 || (len(sections) == 1 && sections[0] != "")
+	var items []list.Item
+	for _, section := range sections {
+		collapsed := l.collapsed[section] && !l.expanded
+		if showHeaders {
+			name := section
+			if name == "" {
+				name = "Other"
+			}
+			items = append(items, groupItem{name: name, count: len(members[section]), collapsed: collapsed})
+		}
+		if !collapsed {
+			items = append(items, members[section]...)
+		}
+	}
+
+	// Set first, as SetItems may refilter.
+	l.list.Filter = workflowFilter(items)
+	l.list.SetItems(items)
+}
+
+// sectionKey maps a header's display name back to its group.
+func sectionKey(g groupItem) string {
+	if g.name == "Other" {
+		return ""
+	}
+	return g.name
+}
+
+func (l *WorkflowList) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok && !l.Filtering() {
+		switch {
+		case key.Matches(msg, listKeys.Toggle):
+			if g, ok := l.list.SelectedItem().(groupItem); ok {
+				section := sectionKey(g)
+				l.collapsed[section] = !l.collapsed[section]
+				index := l.list.Index()
+				l.rebuild()
+				l.list.Select(index)
+				return nil
+			}
+
+		case key.Matches(msg, listKeys.Favorite):
+			if wf := l.Selected(); wf != nil {
+				if _, err := l.store.ToggleFavorite(wf.Key); err != nil {
+					return nil
+				}
+				l.Reload()
+				l.Select(wf.Key)
+			}
+			return nil
+
+		case key.Matches(msg, listKeys.Sort):
+			l.order = (l.order + 1) % 3
+			l.list.NewStatusMessage("Sorted by " + l.order.String())
+			selected := l.Selected()
+			l.rebuild()
+			if selected != nil {
+				l.Select(selected.Key)
+			}
+			return nil
+
+		case msg.String() == "/":
+			// Show collapsed workflows so the filter can find them.
+			l.expanded = true
+			l.rebuild()
+		}
+	}
+
+	before := l.list.FilterState()
+	var cmd tea.Cmd
+	l.list, cmd = l.list.Update(msg)
+	if before != list.Unfiltered && l.list.FilterState() == list.Unfiltered {
+		l.expanded = false
+		l.rebuild()
+	}
+	return cmd
+}
+
+func (l WorkflowList) View() string { return l.list.View() }
+
+// workflowFilter runs workflow.Search over the workflows among items. The
+// list's filter targets line up with its items, so a result's index leads
+// back to its item. Results keep the search ranking, which puts name
+// matches first.
+func workflowFilter(items []list.Item) list.FilterFunc {
+	var workflows []workflow.Workflow
+	var indexes []int
+	for i, item := range items {
+		if wi, ok := item.(workflowItem); ok {
+			workflows = append(workflows, wi.Workflow)
+			indexes = append(indexes, i)
+		}
+	}
+
+	return func(term string, targets []string) []list.Rank {
+		results := workflow.Search(workflows, term)
+		ranks := make([]list.Rank, len(results))
+		for i, result := range results {
+			index := indexes[result.Index]
+			// Highlights are offsets into the title, which puts a star
+			// before a favorite's name.
+			offset := 0
+			if items[index].(workflowItem).favorite {
+				offset = len([]rune("★ "))
+			}
+			matched := make([]int, len(result.NameIndexes))
+			for j, n := range result.NameIndexes {
+				matched[j] = n + offset
+			}
+			ranks[i] = list.Rank{Index: index, MatchedIndexes: matched}
+		}
+		return ranks
+	}
+}
@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ─── Built-in variables ───────────────────────────────────────────────────────
+//
+// Every workflow can use these without setting them, and a variable of the
+// same name hides them. They're computed the first time they're used in a
+// run, and the git ones again after each command step, which may have
+// committed or switched branch. Outside a git repo, or without git, the git
+// ones are empty rather than unset, so a command never gets a literal
+// {{git.sha}}; {{git.branch | default "none"}} gives them a fallback.
+
+// builtins computes the built-in variables for a run.
+type builtins struct {
+	e     *Executor
+	cache map[string]builtinValue
+	// fixed, if set, replaces everything but workflow.key and
+	// workflow.name, and nothing is computed.
+	fixed map[string]string
+}
+
+// WithBuiltins fixes the built-in variables to values instead of computing
+// them, for runs that shouldn't depend on the machine, like tests. Built-ins
+// missing from values are unset.
+func WithBuiltins(values map[string]string) Option {
+	return func(e *Executor) { e.builtins.fixed = values }
+}
+
+type builtinValue struct {
+	value string
+	ok    bool
+}
+
+// builtinFuncs compute the cached built-ins.
+var builtinFuncs = map[string]func() (string, bool){
+	"git.branch":    gitOutput("rev-parse", "--abbrev-ref", "HEAD"),
+	"git.sha":       gitOutput("rev-parse", "HEAD"),
+	"git.short_sha": gitOutput("rev-parse", "--short", "HEAD"),
+	"git.root":      gitOutput("rev-parse", "--show-toplevel"),
+	"git.dirty":     gitDirty,
+	"cwd": func() (string, bool) {
+		dir, err := os.Getwd()
+		return dir, err == nil
+	},
+	"user": currentUser,
+	"hostname": func() (string, bool) {
+		name, err := os.Hostname()
+		return name, err == nil
+	},
+	"os":   func() (string, bool) { return runtime.GOOS, true },
+	"arch": func() (string, bool) { return runtime.GOARCH, true },
+}
+
+func (b *builtins) lookup(name string) (string, bool) {
+	switch name {
+	case "workflow.key", "workflow.name":
+		if b.e.workflow == nil {
+			return "", false
+		}
+		if name == "workflow.key" {
+			return b.e.workflow.Key, true
+		}
+		return b.e.workflow.Name, true
+	}
+
+	if key, ok := strings.CutPrefix(name, "env."); ok {
+		// Env captured by earlier steps wins, as it does for commands.
+		if value, ok := b.e.env.Get(key); ok {
+			return value, true
+		}
+		if b.fixed == nil {
+			return os.LookupEnv(key)
+		}
+	}
+	if b.fixed != nil {
+		value, ok := b.fixed[name]
+		return value, ok
+	}
+
+	if name == "now" {
+		return time.Now().Format(time.RFC3339), true
+	}
+
+	compute, ok := builtinFuncs[name]
+	if !ok {
+		return "", false
+	}
+	if v, ok := b.cache[name]; ok {
+		return v.value, v.ok
+	}
+	value, ok := compute()
+	if b.cache == nil {
+		b.cache = make(map[string]builtinValue)
+	}
+	b.cache[name] = builtinValue{value, ok}
+	return value, ok
+}
+
+// refreshGit drops the cached git values.
+func (b *builtins) refreshGit() {
+	for name := range b.cache {
+		if strings.HasPrefix(name, "git.") {
+			delete(b.cache, name)
+		}
+	}
+}
+
+func (b *builtins) reset() {
+	b.cache = nil
+}
+
+// gitOutput runs git, for its trimmed output, or "" if it fails.
+func gitOutput(args ...string) func() (string, bool) {
+	return func() (string, bool) {
+		out, err := exec.Command("git", args...).Output()
+		if err != nil {
+			return "", true
+		}
+		return strings.TrimSpace(string(out)), true
+	}
+}
+
+// gitDirty is "true" if the work tree has uncommitted changes, and "" if
+// there isn't one.
+func gitDirty() (string, bool) {
+	out, err := exec.Command("git", "status", "--porcelain").Output()
+	if err != nil {
+		return "", true
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		return "true", true
+	}
+	return "false", true
+}
+
+func currentUser() (string, bool) {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username, true
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
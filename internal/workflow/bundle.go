@@ -0,0 +1,348 @@
+package workflow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ─── Bundles ──────────────────────────────────────────────────────────────────
+//
+// A bundle is a YAML file of workflows for sharing, written by
+// `cmdr export` and read by `cmdr import`.
+
+// BundleVersion is the bundle format written by WriteBundle.
+const BundleVersion = 1
+
+// Bundle is a set of workflows exported together, with the fragments they
+// use.
+type Bundle struct {
+	Version   int                 `yaml:"cmdr_bundle"`
+	Exported  time.Time           `yaml:"exported,omitempty"`
+	Workflows []Workflow          `yaml:"workflows"`
+	Fragments map[string]Fragment `yaml:"fragments,omitempty"`
+}
+
+// Bundle collects the workflows with the given keys, in that order, and
+// the fragments they use.
+func (s *Store) Bundle(keys []string) (*Bundle, error) {
+	workflows, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]Workflow, len(workflows))
+	for _, w := range workflows {
+		byKey[w.Key] = w
+	}
+
+	b := &Bundle{Version: BundleVersion, Exported: time.Now().UTC().Truncate(time.Second)}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		w, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("workflow not found: %s", key)
+		}
+		if !seen[key] {
+			seen[key] = true
+			b.Workflows = append(b.Workflows, w)
+		}
+	}
+
+	fragments, err := s.Fragments()
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, w := range b.Workflows {
+		pending = append(pending, w.fragmentsUsed()...)
+	}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		fragment, ok := fragments[name]
+		if _, done := b.Fragments[name]; !ok || done {
+			continue
+		}
+		if b.Fragments == nil {
+			b.Fragments = make(map[string]Fragment)
+		}
+		b.Fragments[name] = fragment
+		pending = append(pending, stepFragments(fragment.Steps)...)
+	}
+	return b, nil
+}
+
+// MissingBases returns the workflows extended by ones in the bundle but
+// not in it themselves, which must already exist wherever it's imported.
+func (b *Bundle) MissingBases() []string {
+	keys := make(map[string]bool, len(b.Workflows))
+	for _, w := range b.Workflows {
+		keys[w.Key] = true
+	}
+	var missing []string
+	for _, w := range b.Workflows {
+		if w.Extends != "" && !keys[w.Extends] && !slices.Contains(missing, w.Extends) {
+			missing = append(missing, w.Extends)
+		}
+	}
+	return missing
+}
+
+// fragmentsUsed returns the fragments a workflow's steps and insertions
+// use directly.
+func (w *Workflow) fragmentsUsed() []string {
+	names := stepFragments(w.allSteps())
+	for _, steps := range w.Insert {
+		names = append(names, stepFragments(steps)...)
+	}
+	return names
+}
+
+func stepFragments(steps []Step) []string {
+	var names []string
+	for _, step := range steps {
+		if step.Use != "" {
+			names = append(names, step.Use)
+		}
+	}
+	return names
+}
+
+// WriteBundle writes b in the given format.
+func WriteBundle(w io.Writer, b *Bundle, f Format) error {
+	if f == FormatJSON {
+		data, err := Marshal(b, f)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(b); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadBundle reads a bundle in YAML or JSON. A plain list of workflows, as
+// in the store's own file, is accepted too. Every workflow is validated.
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b, _, err := decodeFile(data, DetectFormat(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	seen := make(map[string]bool, len(b.Workflows))
+	for i := range b.Workflows {
+		w := &b.Workflows[i]
+		if w.Key == "" {
+			w.Key = Slugify(w.Name)
+		}
+		if err := w.Validate(); err != nil {
+			return nil, fmt.Errorf("workflow %q: %w", w.Key, err)
+		}
+		if seen[w.Key] {
+			return nil, fmt.Errorf("bundle has two workflows with key %q", w.Key)
+		}
+		seen[w.Key] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(b.Fragments)) {
+		fragment := b.Fragments[name]
+		if err := fragment.Validate(); err != nil {
+			return nil, fmt.Errorf("fragment %q: %w", name, err)
+		}
+	}
+	return b, nil
+}
+
+// decodeFile decodes a workflow file, which is either a plain list of
+// workflows or a bundle, returning it as a bundle along with the parsed
+// node of its top-level value.
+func decodeFile(data []byte, f Format) (*Bundle, *yaml.Node, error) {
+	root, err := parseNode(data, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root == nil {
+		return nil, nil, errors.New("empty file")
+	}
+
+	b := &Bundle{}
+	switch root.Kind {
+	case yaml.SequenceNode:
+		b.Version = BundleVersion
+		if err := root.Decode(&b.Workflows); err != nil {
+			return nil, nil, err
+		}
+	case yaml.MappingNode:
+		if err := root.Decode(b); err != nil {
+			return nil, nil, err
+		}
+		if b.Version == 0 {
+			return nil, nil, errors.New("missing cmdr_bundle version")
+		}
+		if b.Version > BundleVersion {
+			return nil, nil, fmt.Errorf("bundle version %d is newer than this cmdr supports (%d)", b.Version, BundleVersion)
+		}
+	default:
+		return nil, nil, errors.New("expected a list of workflows")
+	}
+	return b, root, nil
+}
+
+// ─── Conflicts ────────────────────────────────────────────────────────────────
+
+// ConflictAction says what to do with an imported workflow whose key is
+// already taken.
+type ConflictAction string
+
+const (
+	ConflictSkip      ConflictAction = "skip"
+	ConflictOverwrite ConflictAction = "overwrite"
+	ConflictRename    ConflictAction = "rename"
+)
+
+// ConflictActions lists the valid conflict actions.
+var ConflictActions = []ConflictAction{ConflictSkip, ConflictOverwrite, ConflictRename}
+
+// ParseConflictAction parses a conflict action name.
+func ParseConflictAction(s string) (ConflictAction, error) {
+	for _, a := range ConflictActions {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("unknown conflict action %q (want skip, overwrite or rename)", s)
+}
+
+// FreeKey returns key with the first numeric suffix that isn't taken, such
+// as deploy-2, or key itself if it's free. Keys in reserved count as taken.
+func (s *Store) FreeKey(key string, reserved map[string]bool) (string, error) {
+	workflows, err := s.readAll()
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(workflows)+len(reserved))
+	for _, w := range workflows {
+		taken[w.Key] = true
+	}
+	for k := range reserved {
+		taken[k] = true
+	}
+
+	if !taken[key] {
+		return key, nil
+	}
+	for n := 2; ; n++ {
+		candidate := key + "-" + strconv.Itoa(n)
+		if !taken[candidate] {
+			return candidate, nil
+		}
+	}
+}
+
+// DiffWorkflows compares two workflows as YAML, returning a line diff with
+// a few lines of context around each change, or "" if they're the same.
+// Removed lines start with "-", added ones with "+".
+func DiffWorkflows(old, new *Workflow) (string, error) {
+	a, err := yaml.Marshal(old)
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.Marshal(new)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(a, b) {
+		return "", nil
+	}
+	return diffLines(splitLines(string(a)), splitLines(string(b)), 3), nil
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines diffs a and b by their longest common subsequence, which is
+// fine for files the size of a workflow. Unchanged runs longer than twice
+// context are cut down to context lines either side and a "…" marker.
+func diffLines(a, b []string, context int) string {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	// Keep unchanged lines within context of a change.
+	keep := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for c := max(0, k-context); c <= min(len(lines)-1, k+context); c++ {
+			keep[c] = true
+		}
+	}
+
+	var sb strings.Builder
+	elided := false
+	for k, l := range lines {
+		if !keep[k] {
+			if !elided {
+				sb.WriteString(" …\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		sb.WriteByte(l.op)
+		sb.WriteString(l.text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -2,49 +2,63 @@ package workflow
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // ─── Command ──────────────────────────────────────────────────────────────────
 
-func (e *Executor) executeCommand(step Step, stepNum, totalSteps int) error {
+func (e *Executor) executeCommand(ctx context.Context, step Step, stepNum, totalSteps int) error {
 	command := e.parser.Parse(step.Command)
 
-	if step.Description != "" {
+	stdout, stderr := e.display.stdout(), e.display.stderr()
+
+	switch {
+	case step.Description != "":
 		desc := e.parser.Parse(step.Description)
-		fmt.Printf("[%d/%d] %s\n", stepNum, totalSteps, desc)
-	} else {
-		fmt.Printf("[%d/%d] Running: %s\n", stepNum, totalSteps, command)
+		fmt.Fprintf(stdout, "[%d/%d] %s\n", stepNum, totalSteps, desc)
+	case step.Script != "":
+		fmt.Fprintf(stdout, "[%d/%d] Running script (%s)\n", stepNum, totalSteps, shellFor(e.workflow, step))
+	default:
+		fmt.Fprintf(stdout, "[%d/%d] Running: %s\n", stepNum, totalSteps, command)
 	}
 
-	if step.Interactive {
-		cmd := exec.Command("sh", "-c", command)
-		cmd.Env = e.env.Environ()
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	stdout, flushOut := e.observeOutput(stdout, stepNum, "stdout")
+	stderr, flushErr := e.observeOutput(stderr, stepNum, "stderr")
+	defer flushErr()
+	defer flushOut()
+
+	proc, cleanup, err := e.newCommand(step, command)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	cmd := proc.Cmd
 
-		if err := cmd.Run(); err != nil && !step.IgnoreError {
+	if step.Interactive {
+		err := e.runner.Run(ctx, proc)
+		e.exitCode = exitCode(err)
+		if err != nil && !step.IgnoreError {
 			return fmt.Errorf("command failed: %w", err)
 		}
 		return nil
 	}
 
 	if step.CaptureEnv {
-		cmd := exec.Command("sh", "-c", command)
-		cmd.Env = e.env.Environ()
-		cmd.Stdin = os.Stdin
+		cmd.Stdin = e.display.stdin()
 
 		var buf bytes.Buffer
-		cmd.Stdout = io.MultiWriter(os.Stdout, &buf)
-		cmd.Stderr = io.MultiWriter(os.Stderr, &buf)
+		cmd.Stdout = io.MultiWriter(stdout, &buf)
+		cmd.Stderr = io.MultiWriter(stderr, &buf)
 
-		err := cmd.Run()
-		if err != nil && !step.IgnoreError {
+		err := e.runner.Run(ctx, proc)
+		e.exitCode = exitCode(err)
+		if err != nil && (!step.IgnoreError || ctx.Err() != nil) {
 			return fmt.Errorf("command failed: %w", err)
 		}
 
@@ -60,38 +74,98 @@ func (e *Executor) executeCommand(step Step, stepNum, totalSteps int) error {
 	}
 
 	if step.CaptureOutput {
-		cmd := exec.Command("sh", "-c", command)
-		cmd.Env = e.env.Environ()
+		var outBuf, errBuf bytes.Buffer
+		cmd.Stdout = &outBuf
+		cmd.Stderr = &errBuf
 
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
+		err := e.runner.Run(ctx, proc)
+		e.exitCode = exitCode(err)
 
-		err := cmd.Run()
-
-		output := strings.TrimSpace(stdout.String())
+		output := strings.TrimSpace(outBuf.String())
 		if step.OutputVariable != "" {
 			e.parser.Set(step.OutputVariable, output)
 		}
 
 		if err != nil {
-			if !step.IgnoreError {
-				return fmt.Errorf("command failed: %w\nStderr: %s", err, stderr.String())
+			if !step.IgnoreError || ctx.Err() != nil {
+				return fmt.Errorf("command failed: %w\nStderr: %s", err, errBuf.String())
 			}
-			fmt.Printf("⚠️  Command failed but continuing: %v\n", err)
+			fmt.Fprintf(stdout, "⚠️  Command failed but continuing: %v\n", err)
 		}
 		return nil
 	}
 
 	// Default: stream output directly, no capture
-	cmd := exec.Command("sh", "-c", command)
-	cmd.Env = e.env.Environ()
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-	if err := cmd.Run(); err != nil && !step.IgnoreError {
+	err = e.runner.Run(ctx, proc)
+	e.exitCode = exitCode(err)
+	if err != nil && (!step.IgnoreError || ctx.Err() != nil) {
 		return fmt.Errorf("command failed: %w", err)
 	}
 
 	return nil
 }
+
+// newCommand prepares a command step to run in its shell and working
+// directory, with the workflow and step env overrides applied on top of
+// the workflow's env. Script bodies are written to a temp file, which
+// cleanup removes.
+func (e *Executor) newCommand(step Step, command string) (*Process, func(), error) {
+	shell := shellFor(e.workflow, step)
+	body := command
+	if step.Script != "" {
+		body = e.parser.Parse(step.Script)
+	}
+	proc := &Process{Command: body, Interactive: step.Interactive}
+	if strictFor(e.workflow, step) && shell.posix() {
+		body = shell.strictPrelude() + body
+	}
+
+	cleanup := func() {}
+	var argv []string
+	if step.Script != "" {
+		path, err := writeScript(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() { os.Remove(path) }
+		argv = shell.argv("", path)
+	} else {
+		argv = shell.argv(body, "")
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+
+	dir := resolveDir("", e.parser.Parse(e.workflow.Dir))
+	cmd.Dir = resolveDir(dir, e.parser.Parse(step.Dir))
+
+	env := make(map[string]string, len(e.workflow.Env)+len(step.Env))
+	for k, v := range e.workflow.Env {
+		env[k] = e.parser.Parse(v)
+	}
+	for k, v := range step.Env {
+		env[k] = e.parser.Parse(v)
+	}
+	cmd.Env = withEnv(e.env.Environ(), env, step.UnsetEnv)
+
+	proc.Cmd = cmd
+	return proc, cleanup, nil
+}
+
+// resolveDir resolves dir against base, expanding a leading ~. An empty
+// dir means base itself, and an empty base the current directory.
+func resolveDir(base, dir string) string {
+	if dir == "" {
+		return base
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[1:])
+		}
+	}
+	if filepath.IsAbs(dir) || base == "" {
+		return dir
+	}
+	return filepath.Join(base, dir)
+}
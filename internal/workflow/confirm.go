@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -13,10 +14,13 @@ type confirmModel struct {
 	prompt    string
 	confirmed bool
 	done      bool
+	cancelled bool
 }
 
 func (m confirmModel) Init() tea.Cmd { return nil }
 
+func (m confirmModel) finished() bool { return m.done || m.cancelled }
+
 func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -24,13 +28,11 @@ func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "y":
 			m.confirmed = true
 			m.done = true
-			return m, tea.Quit
 		case "n":
 			m.confirmed = false
 			m.done = true
-			return m, tea.Quit
-		case "ctrl+c":
-			return m, tea.Quit
+		case "ctrl+c", "esc":
+			m.cancelled = true
 		}
 	}
 	return m, nil
@@ -40,29 +42,21 @@ func (m confirmModel) View() string {
 	return fmt.Sprintf("%s (y/n): ", m.prompt)
 }
 
-func (e *Executor) executeConfirm(step Step) error {
-	prompt := e.parser.Parse(step.Prompt)
-
-	m := confirmModel{prompt: prompt}
-	p := tea.NewProgram(m)
+func (e *Executor) executeConfirm(ctx context.Context, step Step) error {
+	step = e.templated(step)
 
-	result, err := p.Run()
+	confirmed, err := e.prompter.Confirm(ctx, step)
 	if err != nil {
-		return fmt.Errorf("confirm failed: %w", err)
-	}
-
-	final := result.(confirmModel)
-	if !final.done {
-		return fmt.Errorf("confirm cancelled")
+		return err
 	}
 
 	answer := "false"
 	label := "No"
-	if final.confirmed {
+	if confirmed {
 		answer = "true"
 		label = "Yes"
 	}
-	fmt.Printf("  ✔ %s\n\n", label)
+	e.answered(step, step.Prompt, label, answer)
 
 	e.parser.Set(step.Variable, answer)
 	return nil
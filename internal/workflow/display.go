@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptModel is the interactive UI for a single step. Prompts never quit
+// the program themselves; they report when the user has answered or
+// cancelled so whichever program is hosting them can move on.
+type promptModel interface {
+	tea.Model
+	finished() bool
+}
+
+// display is where the executor sends progress, command output and prompts.
+// The plain display writes inline to the terminal and runs a short-lived
+// program per prompt; the run view hosts the whole workflow in a single
+// full-screen program.
+type display interface {
+	start(workflow *Workflow)
+	stepStarted(index int, label string)
+	stepSkipped(index int, label string)
+	stepFinished(index int, err error)
+	finish(err error)
+
+	stdin() io.Reader
+	stdout() io.Writer
+	stderr() io.Writer
+	// prompt shows m until it's finished, or until ctx is cancelled.
+	prompt(ctx context.Context, m promptModel) (promptModel, error)
+	answered(prompt, answer string)
+	// exec gives cmd the terminal's streams and calls run to run it.
+	exec(cmd *exec.Cmd, run func() error) error
+}
+
+// ─── Plain display ────────────────────────────────────────────────────────────
+
+type plainDisplay struct {
+	in     io.Reader
+	out    io.Writer
+	errOut io.Writer
+	total  int
+}
+
+func (d *plainDisplay) start(workflow *Workflow) {
+	d.total = len(workflow.allSteps())
+	fmt.Fprintf(d.out, "\nRunning workflow: %s\n", workflow.Name)
+	if workflow.Description != "" {
+		fmt.Fprintf(d.out, "   %s\n", workflow.Description)
+	}
+	fmt.Fprintln(d.out)
+}
+
+func (d *plainDisplay) stepStarted(index int, label string) {}
+
+func (d *plainDisplay) stepSkipped(index int, label string) {
+	fmt.Fprintf(d.out, "Skipping step %d/%d (condition not met)\n", index+1, d.total)
+}
+
+func (d *plainDisplay) stepFinished(index int, err error) {}
+
+func (d *plainDisplay) finish(err error) {
+	if err == nil {
+		fmt.Fprintln(d.out, "\n✅ Workflow completed successfully!")
+	}
+}
+
+func (d *plainDisplay) stdin() io.Reader  { return d.in }
+func (d *plainDisplay) stdout() io.Writer { return d.out }
+func (d *plainDisplay) stderr() io.Writer { return d.errOut }
+
+func (d *plainDisplay) prompt(ctx context.Context, m promptModel) (promptModel, error) {
+	result, err := tea.NewProgram(promptHost{model: m}, tea.WithContext(ctx),
+		tea.WithInput(d.in), tea.WithOutput(d.out)).Run()
+	if err != nil {
+		return nil, err
+	}
+	return result.(promptHost).model, nil
+}
+
+func (d *plainDisplay) answered(prompt, answer string) {
+	fmt.Fprintf(d.out, "\n  ✔  %s\n\n", answer)
+}
+
+func (d *plainDisplay) exec(cmd *exec.Cmd, run func() error) error {
+	cmd.Stdin = d.in
+	cmd.Stdout = d.out
+	cmd.Stderr = d.errOut
+	return run()
+}
+
+// promptHost runs a single prompt as its own program, quitting as soon as
+// the prompt is answered or cancelled.
+type promptHost struct {
+	model promptModel
+}
+
+func (h promptHost) Init() tea.Cmd { return h.model.Init() }
+
+func (h promptHost) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := h.model.Update(msg)
+	h.model = m.(promptModel)
+	if h.model.finished() {
+		return h, tea.Quit
+	}
+	return h, cmd
+}
+
+func (h promptHost) View() string { return h.model.View() }
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"os"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -124,3 +125,37 @@ func (e *WorkflowEnv) ParseAndApply(output string) {
 		e.Set(key, value)
 	}
 }
+
+// withEnv applies set and unset to an environ slice. Keys are applied in
+// sorted order so the result is stable.
+func withEnv(environ []string, set map[string]string, unset []string) []string {
+	if len(set) == 0 && len(unset) == 0 {
+		return environ
+	}
+
+	drop := make(map[string]bool, len(set)+len(unset))
+	for k := range set {
+		drop[k] = true
+	}
+	for _, k := range unset {
+		drop[k] = true
+	}
+
+	result := make([]string, 0, len(environ)+len(set))
+	for _, entry := range environ {
+		key, _, _ := strings.Cut(entry, "=")
+		if !drop[key] {
+			result = append(result, entry)
+		}
+	}
+
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, k+"="+set[k])
+	}
+	return result
+}
@@ -0,0 +1,219 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened during a run.
+type EventType string
+
+const (
+	EventWorkflowStarted  EventType = "workflow_started"
+	EventStepStarted      EventType = "step_started"
+	EventStepSkipped      EventType = "step_skipped"
+	EventPromptAnswered   EventType = "prompt_answered"
+	EventOutputLine       EventType = "output_line"
+	EventStepFinished     EventType = "step_finished"
+	EventWorkflowFinished EventType = "workflow_finished"
+)
+
+// Event is one thing that happened during a run. Only the fields that
+// apply to its type are set.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// Workflow events.
+	Workflow string // key
+	Name     string
+	Steps    int // including finally steps
+
+	// Step events. Step numbers start at 1 and count finally steps after
+	// the main ones.
+	Step     int
+	StepType StepType
+	Label    string
+
+	// Prompt answers. Answer is the value stored in Variable.
+	Variable string
+	Prompt   string
+	Answer   string
+
+	// Command output, one line at a time. Stream is "stdout" or "stderr".
+	Stream string
+	Line   string
+
+	// Step and workflow results. ExitCode is set for command steps whose
+	// process ran to completion.
+	ExitCode *int
+	Duration time.Duration
+	Err      error
+}
+
+// Observer receives the events of a run. Calls are serialized, in the
+// order the events happened.
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc adapts a function to an Observer.
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) Observe(event Event) { f(event) }
+
+// WithObserver adds an observer for every run's events.
+func WithObserver(o Observer) Option {
+	return func(e *Executor) { e.observers = append(e.observers, o) }
+}
+
+// emit stamps an event and sends it to every observer.
+func (e *Executor) emit(event Event) {
+	if len(e.observers) == 0 {
+		return
+	}
+	event.Time = time.Now()
+
+	e.observeMu.Lock()
+	defer e.observeMu.Unlock()
+	for _, o := range e.observers {
+		o.Observe(event)
+	}
+}
+
+// answered shows a prompt's answer and reports it. shown is what the user
+// picked, value what the variable gets; they differ for select options.
+func (e *Executor) answered(step Step, prompt, shown, value string) {
+	e.display.answered(prompt, shown)
+	e.emit(Event{Type: EventPromptAnswered, Variable: step.Variable, Prompt: prompt, Answer: value})
+}
+
+// exitCode returns the exit code of a process that ran to completion.
+// Runners report one through an error with an ExitCode method, as
+// *exec.ExitError has.
+func exitCode(err error) *int {
+	code := 0
+	var exitErr interface{ ExitCode() int }
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		code = exitErr.ExitCode()
+	default:
+		return nil
+	}
+	return &code
+}
+
+// ─── Output lines ─────────────────────────────────────────────────────────────
+
+// outputWriter reports a command's output as output_line events.
+type outputWriter struct {
+	e      *Executor
+	step   int
+	stream string
+	buf    bytes.Buffer
+}
+
+// observeOutput wraps w so that what the command writes is also reported
+// line by line. flush reports a trailing partial line once it exits.
+func (e *Executor) observeOutput(w io.Writer, step int, stream string) (io.Writer, func()) {
+	if len(e.observers) == 0 {
+		return w, func() {}
+	}
+	ow := &outputWriter{e: e, step: step, stream: stream}
+	return io.MultiWriter(w, ow), ow.flush
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			w.buf.WriteString(line)
+			break
+		}
+		w.send(line)
+	}
+	return len(p), nil
+}
+
+func (w *outputWriter) flush() {
+	if w.buf.Len() > 0 {
+		w.send(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *outputWriter) send(line string) {
+	w.e.emit(Event{Type: EventOutputLine, Step: w.step, Stream: w.stream, Line: cleanLine(line)})
+}
+
+// ─── JSON ─────────────────────────────────────────────────────────────────────
+
+// jsonEvent is an Event as it is written in a JSON stream.
+type jsonEvent struct {
+	Type       EventType `json:"type"`
+	Time       time.Time `json:"time"`
+	Workflow   string    `json:"workflow,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Steps      int       `json:"steps,omitempty"`
+	Step       int       `json:"step,omitempty"`
+	StepType   StepType  `json:"step_type,omitempty"`
+	Label      string    `json:"label,omitempty"`
+	Variable   string    `json:"variable,omitempty"`
+	Prompt     string    `json:"prompt,omitempty"`
+	Answer     *string   `json:"answer,omitempty"`
+	Stream     string    `json:"stream,omitempty"`
+	Line       *string   `json:"line,omitempty"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+	DurationMS *int64    `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type jsonObserver struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONObserver returns an observer that writes each event to w as a
+// line of JSON.
+func NewJSONObserver(w io.Writer) Observer {
+	return &jsonObserver{enc: json.NewEncoder(w)}
+}
+
+func (o *jsonObserver) Observe(event Event) {
+	je := jsonEvent{
+		Type:     event.Type,
+		Time:     event.Time,
+		Workflow: event.Workflow,
+		Name:     event.Name,
+		Steps:    event.Steps,
+		Step:     event.Step,
+		StepType: event.StepType,
+		Label:    event.Label,
+		Variable: event.Variable,
+		Prompt:   event.Prompt,
+		Stream:   event.Stream,
+		ExitCode: event.ExitCode,
+	}
+	switch event.Type {
+	case EventPromptAnswered:
+		je.Answer = &event.Answer
+	case EventOutputLine:
+		je.Line = &event.Line
+	case EventStepFinished, EventWorkflowFinished:
+		ms := event.Duration.Milliseconds()
+		je.DurationMS = &ms
+	}
+	if event.Err != nil {
+		je.Error = event.Err.Error()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.enc.Encode(je)
+}
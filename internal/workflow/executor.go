@@ -1,43 +1,211 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/kevmul/cmdr/internal/template"
 )
 
 // Executor runs workflows
 type Executor struct {
-	parser *template.Parser
-	env    *WorkflowEnv
+	parser  *template.Parser
+	env     *WorkflowEnv
+	display display
+	runView bool
+	grace   time.Duration
+
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	prompter Prompter
+	runner   Runner
+
+	observers []Observer
+	observeMu sync.Mutex
+
+	// exitCode is the exit code of the current command step's process.
+	exitCode *int
+
+	// workflow is the one currently running.
+	workflow *Workflow
+
+	// interrupt cancels the current run, as if cmdr had caught sig.
+	interrupt func(sig os.Signal)
+
+	// builtins provides {{git.branch}} and the like.
+	builtins *builtins
+
+	// presets are variables supplied before the run, such as workflow
+	// params from the command line.
+	presets map[string]string
+}
+
+// Option configures an Executor.
+type Option func(*Executor)
+
+// WithStdin sets where prompts and commands read input. Defaults to
+// os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(e *Executor) { e.stdin = r }
+}
+
+// WithStdout sets where progress and command output go. Defaults to
+// os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(e *Executor) { e.stdout = w }
+}
+
+// WithStderr sets where command errors go. Defaults to os.Stderr.
+func WithStderr(w io.Writer) Option {
+	return func(e *Executor) { e.stderr = w }
+}
+
+// WithPrompter sets what answers input, select, confirm and file steps.
+// Defaults to interactive prompts.
+func WithPrompter(p Prompter) Option {
+	return func(e *Executor) { e.prompter = p }
+}
+
+// WithRunner sets what spawns command steps. Defaults to running them as
+// local processes.
+func WithRunner(r Runner) Option {
+	return func(e *Executor) { e.runner = r }
+}
+
+// WithRunView switches between the full-screen run view and the plain
+// inline output. Plain output is the default.
+func WithRunView(enabled bool) Option {
+	return func(e *Executor) { e.runView = enabled }
+}
+
+// WithGracePeriod sets how long an interrupted command has to exit before
+// it is killed.
+func WithGracePeriod(d time.Duration) Option {
+	return func(e *Executor) { e.grace = d }
 }
 
 // NewExecutor creates a new workflow executor
-func NewExecutor() *Executor {
-	return &Executor{
+func NewExecutor(opts ...Option) *Executor {
+	e := &Executor{
 		parser: template.NewParser(),
 		env:    NewWorkflowEnv(),
+		grace:  DefaultGracePeriod,
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
 	}
+	e.builtins = &builtins{e: e}
+	e.parser.SetFallback(e.builtins.lookup)
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.prompter == nil {
+		e.prompter = tuiPrompter{e: e}
+	}
+	if e.runner == nil {
+		e.runner = processRunner{e: e}
+	}
+	return e
+}
+
+// SetVariables sets variables for the next run. Prompting steps for these
+// variables are answered with the given values instead of asking.
+func (e *Executor) SetVariables(vars map[string]string) {
+	e.presets = vars
+}
+
+// Variable returns the value a variable had at the end of the last run.
+func (e *Executor) Variable(name string) (string, bool) {
+	return e.parser.Get(name)
 }
 
-// Execute runs a workflow
-func (e *Executor) Execute(workflow *Workflow) error {
+// Execute runs a workflow. Cancelling ctx interrupts the running command
+// and skips the remaining steps, but finally steps still run; the error is
+// then an *Interrupted.
+func (e *Executor) Execute(ctx context.Context, workflow *Workflow) error {
 	e.parser.Reset()
 	e.env.Reset()
+	e.builtins.reset()
+	for name, value := range workflow.Vars {
+		e.parser.Set(name, value)
+	}
+	for name, value := range e.presets {
+		e.parser.Set(name, value)
+	}
+
+	if e.runView {
+		return e.executeInRunView(ctx, workflow)
+	}
+
+	e.display = &plainDisplay{in: e.stdin, out: e.stdout, errOut: e.stderr}
+	return e.run(ctx, workflow)
+}
+
+// run executes each step in order, reporting progress to the display, then
+// the finally steps whatever the outcome.
+func (e *Executor) run(ctx context.Context, workflow *Workflow) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	e.interrupt = func(sig os.Signal) { cancel(&Interrupted{Signal: sig}) }
 
-	fmt.Printf("\nRunning workflow: %s\n", workflow.Name)
-	if workflow.Description != "" {
-		fmt.Printf("   %s\n", workflow.Description)
+	e.workflow = workflow
+	e.display.start(workflow)
+	started := time.Now()
+	e.emit(Event{Type: EventWorkflowStarted, Workflow: workflow.Key, Name: workflow.Name, Steps: len(workflow.allSteps())})
+
+	err := e.runSteps(ctx, workflow.Steps, 0)
+	if ctx.Err() != nil {
+		// An interruption wins over the error it caused in the step.
+		err = interruptError(ctx)
+	}
+
+	// Cleanup must not be cut short by the cancellation that triggered it.
+	finallyErr := e.runSteps(context.WithoutCancel(ctx), workflow.Finally, len(workflow.Steps))
+	if err == nil {
+		err = finallyErr
 	}
-	fmt.Println()
 
-	for i, step := range workflow.Steps {
-		if err := e.executeStep(step, i+1, len(workflow.Steps)); err != nil {
+	e.display.finish(err)
+	e.emit(Event{Type: EventWorkflowFinished, Workflow: workflow.Key, Name: workflow.Name, Duration: time.Since(started), Err: err})
+	return err
+}
+
+// runSteps runs steps until one fails or ctx is cancelled. offset is the
+// index of the first step among all the workflow's steps.
+func (e *Executor) runSteps(ctx context.Context, steps []Step, offset int) error {
+	total := len(e.workflow.Steps) + len(e.workflow.Finally)
+
+	for i, step := range steps {
+		if ctx.Err() != nil {
+			return interruptError(ctx)
+		}
+
+		index := offset + i
+		label := e.parser.Parse(step.Label())
+
+		if !e.evaluateCondition(step.Condition) {
+			e.display.stepSkipped(index, label)
+			e.emit(Event{Type: EventStepSkipped, Step: index + 1, StepType: step.Type, Label: label})
+			continue
+		}
+
+		e.display.stepStarted(index, label)
+		e.emit(Event{Type: EventStepStarted, Step: index + 1, StepType: step.Type, Label: label})
+		started := time.Now()
+		e.exitCode = nil
+		err := e.executeStep(ctx, step, index+1, total)
+		e.display.stepFinished(index, err)
+		e.emit(Event{Type: EventStepFinished, Step: index + 1, StepType: step.Type, Label: label,
+			ExitCode: e.exitCode, Duration: time.Since(started), Err: err})
+		if err != nil {
 			return err
 		}
 	}
-
-	fmt.Println("\n✅ Workflow completed successfully!")
 	return nil
 }
 
@@ -63,9 +231,8 @@ func (e *Executor) evaluateCondition(c *Condition) bool {
 	}
 }
 
-func (e *Executor) executeStep(step Step, stepNum, totalSteps int) error {
-	if !e.evaluateCondition(step.Condition) {
-		fmt.Printf("Skipping step %d/%d (condition not met)\n", stepNum, totalSteps)
+func (e *Executor) executeStep(ctx context.Context, step Step, stepNum, totalSteps int) error {
+	if e.answerFromPreset(step) {
 		return nil
 	}
 
@@ -73,14 +240,46 @@ func (e *Executor) executeStep(step Step, stepNum, totalSteps int) error {
 	case StepTypeMessage:
 		return e.executeMessage(step)
 	case StepTypeInput:
-		return e.executeInput(step)
+		return e.executeInput(ctx, step)
 	case StepTypeSelect:
-		return e.executeSelect(step)
+		return e.executeSelect(ctx, step)
 	case StepTypeConfirm:
-		return e.executeConfirm(step)
+		return e.executeConfirm(ctx, step)
 	case StepTypeCommand:
-		return e.executeCommand(step, stepNum, totalSteps)
+		defer e.builtins.refreshGit()
+		return e.executeCommand(ctx, step, stepNum, totalSteps)
+	case StepTypeFile:
+		return e.executeFile(ctx, step)
+	case StepTypeSet:
+		return e.executeSet(step)
 	default:
 		return fmt.Errorf("unknown step type: %s", step.Type)
 	}
 }
+
+// answerFromPreset answers a prompting step whose variable was preset, so
+// `cmdr run deploy staging` doesn't ask for the environment again.
+func (e *Executor) answerFromPreset(step Step) bool {
+	switch step.Type {
+	case StepTypeInput, StepTypeSelect, StepTypeConfirm, StepTypeFile:
+	default:
+		return false
+	}
+
+	value, ok := e.presets[step.Variable]
+	if !ok {
+		return false
+	}
+	e.answered(step, e.parser.Parse(step.Prompt), value, value)
+	return true
+}
+
+// templated returns step with the fields shown in its prompt filled in
+// from the current variables.
+func (e *Executor) templated(step Step) Step {
+	step.Prompt = e.parser.Parse(step.Prompt)
+	step.HelpText = e.parser.Parse(step.HelpText)
+	step.Default = e.parser.Parse(step.Default)
+	step.Root = e.parser.Parse(step.Root)
+	return step
+}
@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Shell syntaxes for WriteExports.
+const (
+	ShellPOSIX      = "sh"
+	ShellFish       = "fish"
+	ShellPowerShell = "pwsh"
+)
+
+// ExportShells lists the shells WriteExports understands.
+var ExportShells = []string{ShellPOSIX, ShellFish, ShellPowerShell}
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Vars returns a copy of the captured env vars.
+func (e *WorkflowEnv) Vars() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	vars := make(map[string]string, len(e.vars))
+	for k, v := range e.vars {
+		vars[k] = v
+	}
+	return vars
+}
+
+// WriteExports writes shell commands that apply the env captured by the
+// last run, followed by a change to the workflow's cd directory if it has
+// one. The caller's shell evaluates them, since a child process can't
+// change its parent's environment. Names that aren't valid shell
+// identifiers are skipped.
+func (e *Executor) WriteExports(w io.Writer, shell string, workflow *Workflow) error {
+	switch shell {
+	case ShellPOSIX, ShellFish, ShellPowerShell:
+	default:
+		return fmt.Errorf("unknown shell %q (want one of %s)", shell, strings.Join(ExportShells, ", "))
+	}
+
+	vars := e.env.Vars()
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if envName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		value := vars[name]
+		switch shell {
+		case ShellPOSIX:
+			fmt.Fprintf(&sb, "export %s=%s\n", name, quotePOSIX(value))
+		case ShellFish:
+			fmt.Fprintf(&sb, "set -gx %s %s\n", name, quoteFish(value))
+		case ShellPowerShell:
+			fmt.Fprintf(&sb, "$env:%s = %s\n", name, quotePowerShell(value))
+		}
+	}
+
+	if dir := e.parser.Parse(workflow.Cd); dir != "" {
+		switch shell {
+		case ShellPOSIX:
+			fmt.Fprintf(&sb, "cd %s\n", quotePOSIX(dir))
+		case ShellFish:
+			fmt.Fprintf(&sb, "cd %s\n", quoteFish(dir))
+		case ShellPowerShell:
+			fmt.Fprintf(&sb, "Set-Location -LiteralPath %s\n", quotePowerShell(dir))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func quotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteFish(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func quotePowerShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,385 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevmul/cmdr/internal/styles"
+)
+
+// ─── File ─────────────────────────────────────────────────────────────────────
+
+const fileMaxVisible = 10
+
+type fileEntry struct {
+	name  string
+	isDir bool
+}
+
+type fileModel struct {
+	prompt     string
+	helpText   string
+	root       string
+	dir        string
+	entries    []fileEntry
+	cursor     int
+	offset     int
+	filters    []string
+	showHidden bool
+	dirOnly    bool
+	multi      bool
+	picked     map[string]bool
+	order      []string
+	selected   []string
+	err        error
+	done       bool
+	cancelled  bool
+}
+
+type fileKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Back   key.Binding
+	Toggle key.Binding
+	Hidden key.Binding
+	Pick   key.Binding
+	Quit   key.Binding
+}
+
+var fileKeys = fileKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("k, up", "Move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("j, down", "Move down"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("l", "right"),
+		key.WithHelp("l, right", "open directory"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("h", "left", "backspace"),
+		key.WithHelp("h, left", "parent directory"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
+	Hidden: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "show/hide dotfiles"),
+	),
+	Pick: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "pick"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "esc", "ctrl+c"),
+		key.WithHelp("q, esc", "cancel"),
+	),
+}
+
+func newFileModel(prompt, helpText, root string, step Step) fileModel {
+	m := fileModel{
+		prompt:     prompt,
+		helpText:   helpText,
+		root:       root,
+		dir:        root,
+		filters:    step.Filters,
+		showHidden: step.ShowHidden,
+		dirOnly:    step.DirOnly,
+		multi:      step.Multiple,
+		picked:     make(map[string]bool),
+	}
+	m.readDir()
+	return m
+}
+
+// readDir loads the entries of the current directory, applying the hidden
+// and glob filters. Directories are always listed so they can be navigated.
+func (m *fileModel) readDir() {
+	m.entries = nil
+	m.cursor = 0
+	m.offset = 0
+
+	if m.dirOnly {
+		m.entries = append(m.entries, fileEntry{name: ".", isDir: true})
+	}
+
+	dirEntries, err := os.ReadDir(m.dir)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+
+	for _, de := range dirEntries {
+		name := de.Name()
+		if !m.showHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		isDir := de.IsDir()
+		if !isDir && de.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(m.dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+
+		if !isDir && (m.dirOnly || !m.matches(name)) {
+			continue
+		}
+		m.entries = append(m.entries, fileEntry{name: name, isDir: isDir})
+	}
+
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		a, b := m.entries[i], m.entries[j]
+		if a.name == "." || b.name == "." {
+			return a.name == "."
+		}
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+}
+
+// matches reports whether a file name passes the step's glob filters.
+// No filters means every file is allowed.
+func (m fileModel) matches(name string) bool {
+	if len(m.filters) == 0 {
+		return true
+	}
+	for _, pattern := range m.filters {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m fileModel) current() (string, fileEntry, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.entries) {
+		return "", fileEntry{}, false
+	}
+	entry := m.entries[m.cursor]
+	return filepath.Join(m.dir, entry.name), entry, true
+}
+
+func (m *fileModel) toggle(path string) {
+	if m.picked[path] {
+		delete(m.picked, path)
+		for i, p := range m.order {
+			if p == path {
+				m.order = append(m.order[:i], m.order[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+	m.picked[path] = true
+	m.order = append(m.order, path)
+}
+
+func (m *fileModel) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+fileMaxVisible {
+		m.offset = m.cursor - fileMaxVisible + 1
+	}
+}
+
+func (m fileModel) Init() tea.Cmd { return nil }
+
+func (m fileModel) finished() bool { return m.done || m.cancelled }
+
+func (m fileModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, fileKeys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(keyMsg, fileKeys.Down):
+		if m.cursor < len(m.entries)-1 {
+			m.cursor++
+		}
+	case key.Matches(keyMsg, fileKeys.Open):
+		if path, entry, ok := m.current(); ok && entry.isDir && entry.name != "." {
+			m.dir = path
+			m.readDir()
+		}
+	case key.Matches(keyMsg, fileKeys.Back):
+		if m.dir != m.root {
+			child := filepath.Base(m.dir)
+			m.dir = filepath.Dir(m.dir)
+			m.readDir()
+			for i, e := range m.entries {
+				if e.name == child {
+					m.cursor = i
+				}
+			}
+		}
+	case key.Matches(keyMsg, fileKeys.Hidden):
+		m.showHidden = !m.showHidden
+		m.readDir()
+	case key.Matches(keyMsg, fileKeys.Toggle):
+		if !m.multi {
+			break
+		}
+		if path, entry, ok := m.current(); ok && (m.dirOnly || !entry.isDir) {
+			m.toggle(filepath.Clean(path))
+		}
+	case key.Matches(keyMsg, fileKeys.Pick):
+		if m.multi && len(m.order) > 0 {
+			m.selected = m.order
+			m.done = true
+			return m, nil
+		}
+		path, entry, ok := m.current()
+		if !ok {
+			break
+		}
+		if entry.isDir && !m.dirOnly {
+			m.dir = path
+			m.readDir()
+			break
+		}
+		m.selected = []string{filepath.Clean(path)}
+		m.done = true
+		return m, nil
+	case key.Matches(keyMsg, fileKeys.Quit):
+		m.cancelled = true
+	}
+
+	m.scroll()
+	return m, nil
+}
+
+func (m fileModel) View() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s:\n", m.prompt)
+	if m.helpText != "" {
+		sb.WriteString(styles.HelpTextStyle.Render(m.helpText) + "\n")
+	}
+
+	location := m.dir
+	if rel, err := filepath.Rel(m.root, m.dir); err == nil {
+		location = filepath.Join(filepath.Base(m.root), rel)
+	}
+	sb.WriteString(styles.MutedTextStyle.Render(location+string(filepath.Separator)) + "\n")
+
+	if m.err != nil {
+		sb.WriteString(styles.ErrorStyle.Render(m.err.Error()) + "\n")
+	} else if len(m.entries) == 0 {
+		sb.WriteString(styles.MutedTextStyle.Render("    (no matching files)") + "\n")
+	}
+
+	end := min(m.offset+fileMaxVisible, len(m.entries))
+	for i := m.offset; i < end; i++ {
+		entry := m.entries[i]
+		name := entry.name
+		if entry.isDir && name != "." {
+			name += string(filepath.Separator)
+		}
+		if m.multi {
+			mark := "   "
+			if m.dirOnly || !entry.isDir {
+				mark = "[ ]"
+			}
+			if m.picked[filepath.Join(m.dir, entry.name)] {
+				mark = "[x]"
+			}
+			name = mark + " " + name
+		}
+
+		if i == m.cursor {
+			cursor := styles.CursorStyle.Render("▶")
+			fmt.Fprintf(&sb, "  %s %s\n", cursor, styles.SelectedItemStyle.Render(name))
+		} else {
+			fmt.Fprintf(&sb, "    %s\n", name)
+		}
+	}
+
+	hint := "↵ pick  l open  h up  . dotfiles  q cancel"
+	if m.multi {
+		hint = "space toggle  " + hint
+	}
+	sb.WriteString(styles.MutedTextStyle.Render(hint) + "\n")
+	return sb.String()
+}
+
+// resolvePaths converts picked absolute paths into the form requested by
+// the step, either absolute (the default) or relative to the current
+// working directory.
+func resolvePaths(paths []string, relative bool) ([]string, error) {
+	if !relative {
+		return paths, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	result := make([]string, len(paths))
+	for i, p := range paths {
+		rel, err := filepath.Rel(cwd, p)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = rel
+	}
+	return result, nil
+}
+
+func (e *Executor) executeFile(ctx context.Context, step Step) error {
+	step = e.templated(step)
+
+	root := step.Root
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("invalid root directory: %w", err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return fmt.Errorf("root is not a directory: %s", root)
+	}
+	step.Root = root
+
+	selected, err := e.prompter.File(ctx, step)
+	if err != nil {
+		return err
+	}
+
+	paths, err := resolvePaths(selected, step.RelativePath)
+	if err != nil {
+		return err
+	}
+
+	separator := step.Separator
+	if separator == "" {
+		separator = " "
+	}
+	value := strings.Join(paths, separator)
+
+	e.answered(step, step.Prompt, strings.Join(paths, ", "), value)
+	e.parser.Set(step.Variable, value)
+	return nil
+}
@@ -0,0 +1,336 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ─── File formats ─────────────────────────────────────────────────────────────
+//
+// Workflow files are YAML or JSON with the same schema. JSON is read into
+// and written from a yaml.Node, so the yaml struct tags are the only field
+// names there are and key order survives a round trip.
+
+// Format is the syntax of a workflow file.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// Formats lists the supported file formats.
+var Formats = []Format{FormatYAML, FormatJSON}
+
+// ParseFormat parses a format name.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want yaml or json)", s)
+}
+
+// FormatOf returns the format a file's extension implies, YAML unless it
+// ends in .json.
+func FormatOf(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// DetectFormat guesses the format of data read without a file name, such
+// as from stdin: JSON if it starts with { or [, YAML otherwise.
+func DetectFormat(data []byte) Format {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// Marshal encodes v in the given format.
+func Marshal(v any, f Format) ([]byte, error) {
+	if f != FormatJSON {
+		return yaml.Marshal(v)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, &node); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// Unmarshal decodes data in the given format into v.
+func Unmarshal(data []byte, f Format, v any) error {
+	root, err := parseNode(data, f)
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		return nil
+	}
+	return root.Decode(v)
+}
+
+// parseNode parses data into the node of its top-level value, or nil for
+// an empty file.
+func parseNode(data []byte, f Format) (*yaml.Node, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	if f == FormatJSON {
+		return parseJSON(data)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+// ─── JSON ─────────────────────────────────────────────────────────────────────
+
+// writeJSON writes a node as compact JSON. Scalars keep the type YAML
+// resolved them to; anything that isn't a bool, number or null is a string.
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, n.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(n.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		var v any = n.Value
+		switch n.ShortTag() {
+		case "!!null":
+			v = nil
+		case "!!bool", "!!int", "!!float":
+			if err := n.Decode(&v); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
+
+// parseJSON parses a JSON document into a yaml.Node tree, recording each
+// value's line and column so errors point into the original file.
+func parseJSON(data []byte) (*yaml.Node, error) {
+	p := jsonParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+	for i, b := range data {
+		if b == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	node, err := p.value()
+	if err != nil {
+		return nil, p.wrap(err)
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("line %d: unexpected data after the top-level value", p.line())
+	}
+	return node, nil
+}
+
+type jsonParser struct {
+	data  []byte
+	dec   *json.Decoder
+	lines []int // offsets at which lines after the first start
+}
+
+// pos returns the line and column of the next token.
+func (p *jsonParser) pos() (line, column int) {
+	off := int(p.dec.InputOffset())
+	for off < len(p.data) && strings.IndexByte(" \t\r\n,:", p.data[off]) >= 0 {
+		off++
+	}
+	i := sort.SearchInts(p.lines, off+1)
+	start := 0
+	if i > 0 {
+		start = p.lines[i-1]
+	}
+	return i + 1, off - start + 1
+}
+
+func (p *jsonParser) line() int {
+	line, _ := p.pos()
+	return line
+}
+
+func (p *jsonParser) wrap(err error) error {
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		i := sort.SearchInts(p.lines, int(syntax.Offset))
+		return fmt.Errorf("line %d: %w", i+1, err)
+	}
+	if errors.Is(err, io.EOF) {
+		return errors.New("unexpected end of JSON input")
+	}
+	return err
+}
+
+func (p *jsonParser) value() (*yaml.Node, error) {
+	line, column := p.pos()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{Line: line, Column: column}
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for p.dec.More() {
+				keyLine, keyColumn := p.pos()
+				key, err := p.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{
+					Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string),
+					Line: keyLine, Column: keyColumn,
+				}, value)
+			}
+		case '[':
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for p.dec.More() {
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		}
+		// The closing delimiter.
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", tok
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!int", tok.String()
+		if strings.ContainsAny(tok.String(), ".eE") {
+			node.Tag = "!!float"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", fmt.Sprint(tok)
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return node, nil
+}
+
+// ─── Unknown fields ───────────────────────────────────────────────────────────
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// unknownFields reports mapping keys under n that don't match a yaml tag
+// of t, which is usually a misspelt field that would otherwise be ignored.
+func unknownFields(n *yaml.Node, t reflect.Type) []error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var errs []error
+	switch {
+	case n.Kind == yaml.AliasNode:
+		return unknownFields(n.Alias, t)
+	case n.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for _, item := range n.Content {
+			errs = append(errs, unknownFields(item, t.Elem())...)
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 1; i < len(n.Content); i += 2 {
+			errs = append(errs, unknownFields(n.Content[i], t.Elem())...)
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			ft, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, fmt.Errorf("line %d: unknown field %q", key.Line, key.Value))
+				continue
+			}
+			errs = append(errs, unknownFields(n.Content[i+1], ft)...)
+		}
+	}
+	return errs
+}
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"github.com/kevmul/cmdr/internal/styles"
 
@@ -11,28 +12,29 @@ import (
 // ─── Input ────────────────────────────────────────────────────────────────────
 
 type inputModel struct {
-	prompt   string
-	helpText string
-	value    string
-	done     bool
+	prompt    string
+	helpText  string
+	value     string
+	done      bool
+	cancelled bool
 }
 
 func (m inputModel) Init() tea.Cmd { return nil }
 
+func (m inputModel) finished() bool { return m.done || m.cancelled }
+
 func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyEnter:
 			m.done = true
-			return m, tea.Quit
 		case tea.KeyBackspace, tea.KeyDelete:
 			if len(m.value) > 0 {
 				m.value = m.value[:len(m.value)-1]
 			}
 		case tea.KeyCtrlC, tea.KeyEsc:
-			m.done = false
-			return m, tea.Quit
+			m.cancelled = true
 		default:
 			if msg.Type == tea.KeyRunes {
 				m.value += string(msg.Runes)
@@ -51,24 +53,15 @@ func (m inputModel) View() string {
 	)
 }
 
-func (e *Executor) executeInput(step Step) error {
-	prompt := e.parser.Parse(step.Prompt)
-	helpText := e.parser.Parse(step.HelpText)
-
-	m := inputModel{helpText: helpText, prompt: fmt.Sprintf("%s:", prompt)}
-	p := tea.NewProgram(m)
+func (e *Executor) executeInput(ctx context.Context, step Step) error {
+	step = e.templated(step)
 
-	result, err := p.Run()
+	value, err := e.prompter.Input(ctx, step)
 	if err != nil {
-		return fmt.Errorf("input failed: %w", err)
-	}
-
-	final := result.(inputModel)
-	if !final.done {
-		return fmt.Errorf("input cancelled")
+		return err
 	}
 
-	fmt.Println() // newline after inline input
-	e.parser.Set(step.Variable, final.value)
+	e.answered(step, step.Prompt, value, value)
+	e.parser.Set(step.Variable, value)
 	return nil
 }
@@ -52,6 +52,6 @@ func (e *Executor) executeMessage(step Step) error {
 	text := e.parser.Parse(step.Prompt)
 	variant := e.parser.Parse(step.Variant)
 	m := messageModel{variant: variant, output: text}
-	fmt.Println(m.View())
+	fmt.Fprintln(e.display.stdout(), m.View())
 	return nil
 }
@@ -0,0 +1,369 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ParamType is the value type of a workflow parameter.
+type ParamType string
+
+const (
+	ParamTypeString ParamType = "string"
+	ParamTypeInt    ParamType = "int"
+	ParamTypeBool   ParamType = "bool"
+)
+
+// Param declares a command-line argument that sets a variable before the
+// workflow runs, e.g. `cmdr run deploy staging --tag v1.2`. Positional
+// params are taken in declaration order; the rest become flags.
+type Param struct {
+	Name       string    `yaml:"name"`
+	Type       ParamType `yaml:"type,omitempty"` // string (default), int or bool
+	Positional bool      `yaml:"positional,omitempty"`
+	Variadic   bool      `yaml:"variadic,omitempty"` // the last positional takes the remaining args, joined by spaces
+	Short      string    `yaml:"short,omitempty"`    // one-letter flag shorthand
+	Default    string    `yaml:"default,omitempty"`
+	Required   bool      `yaml:"required,omitempty"`
+	Help       string    `yaml:"help,omitempty"`
+}
+
+func (p Param) kind() ParamType {
+	if p.Type == "" {
+		return ParamTypeString
+	}
+	return p.Type
+}
+
+// Validate checks a single param's type, shorthand and default.
+func (p Param) Validate() error {
+	var errs []error
+
+	if p.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	switch p.kind() {
+	case ParamTypeString, ParamTypeInt, ParamTypeBool:
+	default:
+		errs = append(errs, fmt.Errorf("unknown type %q", p.Type))
+	}
+	if p.Positional && p.kind() == ParamTypeBool {
+		errs = append(errs, errors.New("bool params must be flags"))
+	}
+	if p.Positional && p.Short != "" {
+		errs = append(errs, errors.New("positional params cannot have a shorthand"))
+	}
+	if p.Variadic && (!p.Positional || p.kind() != ParamTypeString) {
+		errs = append(errs, errors.New("only positional string params can be variadic"))
+	}
+	if len(p.Short) > 1 {
+		errs = append(errs, fmt.Errorf("shorthand %q must be a single letter", p.Short))
+	}
+	if p.Default != "" {
+		if err := p.check(p.Default); err != nil {
+			errs = append(errs, fmt.Errorf("default: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// check reports whether value is valid for the param's type.
+func (p Param) check(value string) error {
+	switch p.kind() {
+	case ParamTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case ParamTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+	}
+	return nil
+}
+
+// reservedParamNames are flags every workflow command already has: its own
+// --set, --export and --help, and cmdr's global flags.
+var reservedParamNames = []string{"set", "export", "help", "plain", "output", "grace-period"}
+
+// validateParams checks each param and that they fit together: names are
+// unique, no required positional follows an optional one and only the last
+// positional is variadic.
+func (w *Workflow) validateParams() []error {
+	var errs []error
+	seen := make(map[string]bool)
+	shorts := make(map[string]bool)
+	optional := false
+	variadic := ""
+
+	for i, p := range w.Params {
+		if err := p.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("param %d: %w", i+1, err))
+		}
+		if slices.Contains(reservedParamNames, p.Name) {
+			errs = append(errs, fmt.Errorf("param %d: name %q is reserved", i+1, p.Name))
+		}
+		if seen[p.Name] {
+			errs = append(errs, fmt.Errorf("param %d: duplicate name %q", i+1, p.Name))
+		}
+		seen[p.Name] = true
+		if p.Short != "" {
+			if shorts[p.Short] || p.Short == "h" {
+				errs = append(errs, fmt.Errorf("param %d: shorthand %q is already in use", i+1, p.Short))
+			}
+			shorts[p.Short] = true
+		}
+		if p.Positional {
+			if p.Required && optional {
+				errs = append(errs, fmt.Errorf("param %d: required %q follows an optional positional param", i+1, p.Name))
+			}
+			if variadic != "" {
+				errs = append(errs, fmt.Errorf("param %d: %q follows the variadic param %q", i+1, p.Name, variadic))
+			}
+			optional = optional || !p.Required
+			if p.Variadic {
+				variadic = p.Name
+			}
+		}
+	}
+	return errs
+}
+
+// FlagSet builds the flags for a workflow's non-positional params, plus
+// --set for presetting any variable and --export for printing the captured
+// env when the run finishes.
+func (w *Workflow) FlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(w.Key, pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	fs.StringArray("set", nil, "set a variable, e.g. --set env=prod (repeatable)")
+	fs.String("export", "", "print the captured env as "+strings.Join(ExportShells, ", ")+" commands to eval")
+	fs.Lookup("export").NoOptDefVal = ShellPOSIX
+
+	for _, p := range w.Params {
+		if p.Positional {
+			continue
+		}
+		switch p.kind() {
+		case ParamTypeInt:
+			def, _ := strconv.Atoi(p.Default)
+			fs.IntP(p.Name, p.Short, def, p.Help)
+		case ParamTypeBool:
+			def, _ := strconv.ParseBool(p.Default)
+			fs.BoolP(p.Name, p.Short, def, p.Help)
+		default:
+			fs.StringP(p.Name, p.Short, p.Default, p.Help)
+		}
+	}
+	return fs
+}
+
+// Bind reads the variables set by params from parsed flags, as built by
+// FlagSet, and the remaining positional arguments. Params that were
+// neither given nor have a default are left unset, so their input steps
+// still prompt. Bool flags are always set, as are variadic params, empty
+// when given nothing, and --set values override everything else.
+func (w *Workflow) Bind(fs *pflag.FlagSet, positional []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, p := range w.Params {
+		if !p.Positional {
+			flag := fs.Lookup(p.Name)
+			if flag == nil {
+				continue
+			}
+			switch {
+			case flag.Changed || p.Default != "" || p.kind() == ParamTypeBool:
+				vars[p.Name] = flag.Value.String()
+			case p.Required:
+				return nil, fmt.Errorf("required flag --%s not set", p.Name)
+			}
+			continue
+		}
+
+		switch {
+		case p.Variadic && len(positional) > 0:
+			vars[p.Name] = strings.Join(positional, " ")
+			positional = nil
+		case len(positional) > 0:
+			if err := p.check(positional[0]); err != nil {
+				return nil, fmt.Errorf("argument %s: %w", p.Name, err)
+			}
+			vars[p.Name] = positional[0]
+			positional = positional[1:]
+		case p.Default != "":
+			vars[p.Name] = p.Default
+		case p.Required:
+			return nil, fmt.Errorf("missing required argument <%s>", p.Name)
+		case p.Variadic:
+			vars[p.Name] = ""
+		}
+	}
+
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	sets, _ := fs.GetStringArray("set")
+	for _, set := range sets {
+		name, value, found := strings.Cut(set, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("--set %q must look like name=value", set)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// Defaults returns the variables set by params with defaults, for runs
+// started without command-line arguments such as from the workflow list.
+// Bool params default to false, and variadic ones to empty.
+func (w *Workflow) Defaults() map[string]string {
+	vars := make(map[string]string)
+	for _, p := range w.Params {
+		switch {
+		case p.Default != "":
+			vars[p.Name] = p.Default
+		case p.kind() == ParamTypeBool:
+			vars[p.Name] = "false"
+		case p.Variadic:
+			vars[p.Name] = ""
+		}
+	}
+	return vars
+}
+
+// Positionals returns the positional params in order.
+func (w *Workflow) Positionals() []Param {
+	var params []Param
+	for _, p := range w.Params {
+		if p.Positional {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// PositionalAt returns the positional param that the i'th argument,
+// counting from 0, sets. A variadic param takes every argument from its
+// position on.
+func (w *Workflow) PositionalAt(i int) (Param, bool) {
+	positionals := w.Positionals()
+	if i < len(positionals) {
+		return positionals[i], true
+	}
+	if n := len(positionals); n > 0 && positionals[n-1].Variadic {
+		return positionals[n-1], true
+	}
+	return Param{}, false
+}
+
+// ArgsUsage renders the positional params for a usage line, e.g.
+// " <env> [region] [files...]".
+func (w *Workflow) ArgsUsage() string {
+	var sb strings.Builder
+	for _, p := range w.Positionals() {
+		name := p.Name
+		if p.Variadic {
+			name += "..."
+		}
+		if p.Required {
+			sb.WriteString(" <" + name + ">")
+		} else {
+			sb.WriteString(" [" + name + "]")
+		}
+	}
+	return sb.String()
+}
+
+// Help describes the workflow for command-line help: its description, its
+// positional arguments and the questions it will ask.
+func (w *Workflow) Help() string {
+	var sb strings.Builder
+	if w.Description != "" {
+		sb.WriteString(w.Description + "\n")
+	} else {
+		sb.WriteString(w.Name + "\n")
+	}
+	sb.WriteString(w.helpSections())
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// helpSections lists the positional arguments and the prompting steps,
+// each section preceded by a blank line.
+func (w *Workflow) helpSections() string {
+	var sb strings.Builder
+
+	var args [][2]string
+	for _, p := range w.Positionals() {
+		name, help := p.Name, p.Help
+		if p.Variadic {
+			name += "..."
+		}
+		if p.Default != "" {
+			help += fmt.Sprintf(" (default %q)", p.Default)
+		}
+		args = append(args, [2]string{name, strings.TrimSpace(help)})
+	}
+	writeSection(&sb, "Arguments", args)
+
+	var prompts [][2]string
+	for _, step := range w.Steps {
+		switch step.Type {
+		case StepTypeInput, StepTypeSelect, StepTypeConfirm, StepTypeFile:
+		default:
+			continue
+		}
+		help := step.Prompt
+		if len(step.Options) > 0 {
+			values := make([]string, len(step.Options))
+			for i, opt := range step.Options {
+				values[i] = opt.OptionValue()
+			}
+			help += " (" + strings.Join(values, ", ") + ")"
+		}
+		prompts = append(prompts, [2]string{step.Variable, strings.TrimSpace(help)})
+	}
+	writeSection(&sb, "Prompts", prompts)
+
+	return sb.String()
+}
+
+func writeSection(sb *strings.Builder, title string, rows [][2]string) {
+	if len(rows) == 0 {
+		return
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row[0]))
+	}
+	fmt.Fprintf(sb, "\n%s:\n", title)
+	for _, row := range rows {
+		fmt.Fprintf(sb, "  %-*s   %s\n", width, row[0], row[1])
+	}
+}
+
+// Usage describes how to call the workflow from the command line. command
+// is the invocation that precedes the arguments, e.g. "cmdr run deploy".
+func (w *Workflow) Usage(command string) string {
+	var sb strings.Builder
+
+	fs := w.FlagSet()
+	usage := command + w.ArgsUsage() + " [flags]"
+
+	if w.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", w.Description)
+	}
+	fmt.Fprintf(&sb, "Usage:\n  %s\n", usage)
+	sb.WriteString(w.helpSections())
+
+	fs.BoolP("help", "h", false, "help for "+w.Key)
+	fmt.Fprintf(&sb, "\nFlags:\n%s", fs.FlagUsages())
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+//go:build !unix
+
+package workflow
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Process groups are a Unix concept; elsewhere only the command itself is
+// signalled, and it shares the terminal with cmdr.
+func setProcessGroup(cmd *exec.Cmd, foreground bool) (restore func()) {
+	return func() {}
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	if sig == os.Kill {
+		return cmd.Process.Kill()
+	}
+	if err := cmd.Process.Signal(sig); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+func killedBy(cmd *exec.Cmd, sig os.Signal) bool { return false }
@@ -0,0 +1,65 @@
+//go:build unix
+
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setProcessGroup starts cmd in a process group of its own. With foreground
+// set, and cmdr in the foreground of its terminal, the group is also given
+// the terminal, so the command can read passwords and run pagers; the
+// returned func takes it back once the command has exited.
+func setProcessGroup(cmd *exec.Cmd, foreground bool) (restore func()) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if !foreground {
+		return func() {}
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return func() {}
+	}
+	fd := int(tty.Fd())
+	own, err := unix.Getpgid(0)
+	if err != nil {
+		tty.Close()
+		return func() {}
+	}
+	if pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP); err != nil || pgrp != own {
+		tty.Close()
+		return func() {}
+	}
+
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = fd
+	return func() {
+		// cmdr is in the background until it has the terminal back, and
+		// taking it from there raises SIGTTOU.
+		signal.Ignore(syscall.SIGTTOU)
+		unix.IoctlSetPointerInt(fd, unix.TIOCSPGRP, own)
+		signal.Reset(syscall.SIGTTOU)
+		tty.Close()
+	}
+}
+
+// signalProcessGroup signals every process in the command's group, so
+// children of the shell are stopped too.
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		s = syscall.SIGTERM
+	}
+	return syscall.Kill(-cmd.Process.Pid, s)
+}
+
+// killedBy reports whether the command was killed by sig.
+func killedBy(cmd *exec.Cmd, sig os.Signal) bool {
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == sig
+}
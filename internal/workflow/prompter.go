@@ -0,0 +1,190 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Prompter answers the steps that ask the user for a value. Each step is
+// passed with its prompt, help text and default templated, and a file
+// step's root resolved to an absolute directory. Returning an error stops
+// the workflow, and a prompt should stop waiting once ctx is cancelled.
+//
+// The default prompter shows interactive prompts on the executor's
+// display; ScriptedPrompter answers from a fixed set of values.
+type Prompter interface {
+	Input(ctx context.Context, step Step) (string, error)
+	Select(ctx context.Context, step Step) (SelectOption, error)
+	Confirm(ctx context.Context, step Step) (bool, error)
+	// File returns the picked paths, absolute.
+	File(ctx context.Context, step Step) ([]string, error)
+}
+
+// ─── Interactive prompter ─────────────────────────────────────────────────────
+
+// tuiPrompter hosts a bubbletea prompt in whichever display the executor is
+// using, inline or in the run view.
+type tuiPrompter struct {
+	e *Executor
+}
+
+func (p tuiPrompter) Input(ctx context.Context, step Step) (string, error) {
+	m := inputModel{helpText: step.HelpText, prompt: fmt.Sprintf("%s:", step.Prompt)}
+
+	result, err := p.e.display.prompt(ctx, m)
+	if err != nil {
+		return "", fmt.Errorf("input failed: %w", err)
+	}
+
+	final := result.(inputModel)
+	if !final.done {
+		return "", fmt.Errorf("input cancelled")
+	}
+	return final.value, nil
+}
+
+func (p tuiPrompter) Select(ctx context.Context, step Step) (SelectOption, error) {
+	m := newSelectModel(step.Prompt, step.HelpText, step.Options, step.Default)
+
+	result, err := p.e.display.prompt(ctx, m)
+	if err != nil {
+		return SelectOption{}, fmt.Errorf("select failed: %w", err)
+	}
+
+	final := result.(selectModel)
+	if !final.done {
+		return SelectOption{}, fmt.Errorf("selection cancelled")
+	}
+	return final.selected, nil
+}
+
+func (p tuiPrompter) Confirm(ctx context.Context, step Step) (bool, error) {
+	m := confirmModel{prompt: step.Prompt}
+
+	result, err := p.e.display.prompt(ctx, m)
+	if err != nil {
+		return false, fmt.Errorf("confirm failed: %w", err)
+	}
+
+	final := result.(confirmModel)
+	if !final.done {
+		return false, fmt.Errorf("confirm cancelled")
+	}
+	return final.confirmed, nil
+}
+
+func (p tuiPrompter) File(ctx context.Context, step Step) ([]string, error) {
+	m := newFileModel(step.Prompt, step.HelpText, step.Root, step)
+
+	result, err := p.e.display.prompt(ctx, m)
+	if err != nil {
+		return nil, fmt.Errorf("file picker failed: %w", err)
+	}
+
+	final := result.(fileModel)
+	if !final.done {
+		return nil, fmt.Errorf("file selection cancelled")
+	}
+	return final.selected, nil
+}
+
+// ─── Scripted prompter ────────────────────────────────────────────────────────
+
+// ScriptedPrompter answers prompts from values keyed by variable name, for
+// tests and unattended runs. A step without an answer takes its default,
+// and fails if it has none.
+type ScriptedPrompter struct {
+	Answers map[string]string
+}
+
+// NewScriptedPrompter creates a prompter that answers from answers.
+func NewScriptedPrompter(answers map[string]string) *ScriptedPrompter {
+	return &ScriptedPrompter{Answers: answers}
+}
+
+func (p *ScriptedPrompter) answer(ctx context.Context, step Step) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if answer, ok := p.Answers[step.Variable]; ok {
+		return answer, nil
+	}
+	if step.Default != "" {
+		return step.Default, nil
+	}
+	return "", fmt.Errorf("no scripted answer for %q", step.Variable)
+}
+
+func (p *ScriptedPrompter) Input(ctx context.Context, step Step) (string, error) {
+	return p.answer(ctx, step)
+}
+
+// Select matches the answer against each option's value, then its text.
+func (p *ScriptedPrompter) Select(ctx context.Context, step Step) (SelectOption, error) {
+	answer, err := p.answer(ctx, step)
+	if err != nil {
+		return SelectOption{}, err
+	}
+	for _, opt := range step.Options {
+		if opt.OptionValue() == answer {
+			return opt, nil
+		}
+	}
+	for _, opt := range step.Options {
+		if opt.Text == answer {
+			return opt, nil
+		}
+	}
+	return SelectOption{}, fmt.Errorf("scripted answer %q for %q is not one of the options", answer, step.Variable)
+}
+
+// Confirm accepts y/yes/n/no as well as anything strconv.ParseBool does.
+func (p *ScriptedPrompter) Confirm(ctx context.Context, step Step) (bool, error) {
+	answer, err := p.answer(ctx, step)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	confirmed, err := strconv.ParseBool(answer)
+	if err != nil {
+		return false, fmt.Errorf("scripted answer %q for %q is not yes or no", answer, step.Variable)
+	}
+	return confirmed, nil
+}
+
+// File splits the answer on the step's separator and resolves each path
+// against the step's root.
+func (p *ScriptedPrompter) File(ctx context.Context, step Step) ([]string, error) {
+	answer, err := p.answer(ctx, step)
+	if err != nil {
+		return nil, err
+	}
+
+	separator := step.Separator
+	if separator == "" {
+		separator = " "
+	}
+	var paths []string
+	for _, path := range strings.Split(answer, separator) {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(step.Root, path)
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("scripted answer has no paths")
+	}
+	return paths, nil
+}
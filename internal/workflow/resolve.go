@@ -0,0 +1,391 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/template"
+	"gopkg.in/yaml.v3"
+)
+
+// ─── Inheritance and fragments ────────────────────────────────────────────────
+//
+// A workflow can extend another, overriding its vars, params and settings
+// and filling its slots with steps of its own, and any workflow can include
+// a fragment, a named list of steps from fragments.yaml, with a `use:`
+// step. Resolving flattens all of this, so the executor only ever sees
+// plain steps.
+
+// Fragment is a reusable list of steps. Its params are given with the `with`
+// of the step that uses it, and replace {{name}} in its steps.
+type Fragment struct {
+	Description string  `yaml:"description,omitempty"`
+	Params      []Param `yaml:"params,omitempty"`
+	Steps       []Step  `yaml:"steps"`
+}
+
+// Validate checks a fragment's params and steps.
+func (f *Fragment) Validate() error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, p := range f.Params {
+		if err := p.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("param %d: %w", i+1, err))
+		}
+		if seen[p.Name] {
+			errs = append(errs, fmt.Errorf("param %d: duplicate name %q", i+1, p.Name))
+		}
+		seen[p.Name] = true
+	}
+	if len(f.Steps) == 0 {
+		errs = append(errs, errors.New("steps are required"))
+	}
+	for i, step := range f.Steps {
+		if step.Slot != "" {
+			errs = append(errs, fmt.Errorf("step %d: fragments can't have slots", i+1))
+			continue
+		}
+		if err := step.Validate(); err != nil {
+			errs = append(errs, prefixErrors(fmt.Sprintf("step %d", i+1), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fragments returns the fragment library, keyed by name.
+func (s *Store) Fragments() (map[string]Fragment, error) {
+	data, err := os.ReadFile(s.fragmentsPath)
+	if os.IsNotExist(err) {
+		return map[string]Fragment{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fragments := map[string]Fragment{}
+	if err := Unmarshal(data, FormatOf(s.fragmentsPath), &fragments); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.fragmentsPath, err)
+	}
+	return fragments, nil
+}
+
+// SaveFragments replaces the fragment library.
+func (s *Store) SaveFragments(fragments map[string]Fragment) error {
+	data, err := Marshal(fragments, FormatOf(s.fragmentsPath))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.fragmentsPath, data, 0644)
+}
+
+// FragmentsPath returns the file the fragment library is kept in.
+func (s *Store) FragmentsPath() string {
+	return s.fragmentsPath
+}
+
+// Resolve returns w flattened against the store: merged with the workflows
+// it extends, its slots filled and its fragments expanded. w itself is left
+// alone, so it can still be edited and saved as written.
+func (s *Store) Resolve(w *Workflow) (*Workflow, error) {
+	r, err := s.resolver()
+	if err != nil {
+		return nil, err
+	}
+	return r.resolve(w)
+}
+
+// LoadResolved loads a workflow by key and resolves it.
+func (s *Store) LoadResolved(key string) (*Workflow, error) {
+	w, err := s.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.Resolve(w)
+}
+
+// ListResolved returns every workflow resolved, or the error resolving it
+// in the matching element of errs.
+func (s *Store) ListResolved() (workflows []Workflow, errs []error, err error) {
+	r, err := s.resolver()
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := s.readAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workflows = make([]Workflow, len(raw))
+	errs = make([]error, len(raw))
+	for i := range raw {
+		resolved, err := r.resolve(&raw[i])
+		if err != nil {
+			workflows[i], errs[i] = raw[i], err
+			continue
+		}
+		workflows[i] = *resolved
+	}
+	return workflows, errs, nil
+}
+
+func (s *Store) resolver() (*resolver, error) {
+	workflows, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	fragments, err := s.Fragments()
+	if err != nil {
+		return nil, err
+	}
+	return newResolver(workflows, fragments), nil
+}
+
+type resolver struct {
+	workflows map[string]*Workflow
+	fragments map[string]Fragment
+}
+
+func newResolver(workflows []Workflow, fragments map[string]Fragment) *resolver {
+	r := &resolver{workflows: make(map[string]*Workflow, len(workflows)), fragments: fragments}
+	for i := range workflows {
+		r.workflows[workflows[i].Key] = &workflows[i]
+	}
+	return r
+}
+
+func (r *resolver) resolve(w *Workflow) (*Workflow, error) {
+	merged, err := r.merged(w, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := make(map[string]bool)
+	var stepsErr, finallyErr error
+	merged.Steps, stepsErr = r.expand(merged.Steps, merged.Insert, filled, nil)
+	merged.Finally, finallyErr = r.expand(merged.Finally, merged.Insert, filled, nil)
+	errs := []error{stepsErr, finallyErr}
+	for _, name := range slices.Sorted(maps.Keys(merged.Insert)) {
+		if !filled[name] {
+			errs = append(errs, fmt.Errorf("insert %q: there's no slot by that name", name))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	merged.Insert = nil
+
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// merged returns w merged over the chain of workflows it extends. chain
+// holds the keys already visited, to catch cycles.
+func (r *resolver) merged(w *Workflow, chain []string) (*Workflow, error) {
+	out := *w
+	if w.Extends == "" {
+		return &out, nil
+	}
+
+	chain = append(chain, w.Key)
+	if slices.Contains(chain, w.Extends) {
+		return nil, fmt.Errorf("extends cycle: %s → %s", strings.Join(chain, " → "), w.Extends)
+	}
+	base, ok := r.workflows[w.Extends]
+	if !ok {
+		return nil, fmt.Errorf("extends %q: no such workflow", w.Extends)
+	}
+	parent, err := r.merged(base, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeWorkflows(parent, w), nil
+}
+
+// mergeWorkflows overlays child on base. The child keeps its own key and
+// name, and its settings win where it has them. Params, env, vars and
+// insertions are merged by name; steps and finally steps are replaced
+// wholesale if the child has any.
+func mergeWorkflows(base, child *Workflow) *Workflow {
+	out := *base
+	out.Key, out.Name, out.Extends = child.Key, child.Name, ""
+
+	override := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	override(&out.Description, child.Description)
+	override(&out.Group, child.Group)
+	override(&out.Cd, child.Cd)
+	override(&out.Dir, child.Dir)
+	if len(child.Tags) > 0 {
+		out.Tags = child.Tags
+	}
+	if len(child.Shell) > 0 {
+		out.Shell = child.Shell
+	}
+	out.Strict = base.Strict || child.Strict
+	if len(child.Steps) > 0 {
+		out.Steps = child.Steps
+	}
+	if len(child.Finally) > 0 {
+		out.Finally = child.Finally
+	}
+
+	out.Params = slices.Clone(base.Params)
+	for _, p := range child.Params {
+		i := slices.IndexFunc(out.Params, func(q Param) bool { return q.Name == p.Name })
+		if i >= 0 {
+			out.Params[i] = p
+		} else {
+			out.Params = append(out.Params, p)
+		}
+	}
+	out.Env = mergeMaps(base.Env, child.Env)
+	out.Vars = mergeMaps(base.Vars, child.Vars)
+	out.Insert = mergeMaps(base.Insert, child.Insert)
+	return &out
+}
+
+func mergeMaps[V any](base, child map[string]V) map[string]V {
+	if len(base) == 0 && len(child) == 0 {
+		return nil
+	}
+	out := maps.Clone(base)
+	if out == nil {
+		out = make(map[string]V, len(child))
+	}
+	maps.Copy(out, child)
+	return out
+}
+
+// expand replaces slots with the steps inserted into them, recording which
+// were filled, and use steps with their fragments. using holds the
+// fragments being expanded, to catch cycles.
+func (r *resolver) expand(steps []Step, insert map[string][]Step, filled map[string]bool, using []string) ([]Step, error) {
+	var out []Step
+	var errs []error
+	for _, step := range steps {
+		switch {
+		case step.Slot != "":
+			filled[step.Slot] = true
+			inserted, err := r.expand(insert[step.Slot], nil, filled, using)
+			if err != nil {
+				errs = append(errs, prefixErrors(fmt.Sprintf("insert %q", step.Slot), err))
+			}
+			out = append(out, inserted...)
+		case step.Use != "":
+			used, err := r.use(step, filled, using)
+			if err != nil {
+				errs = append(errs, prefixErrors(fmt.Sprintf("use %q", step.Use), err))
+			}
+			out = append(out, used...)
+		default:
+			out = append(out, step)
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// prefixErrors puts prefix before each of the errors joined in err.
+func prefixErrors(prefix string, err error) error {
+	var errs []error
+	for _, line := range strings.Split(err.Error(), "\n") {
+		errs = append(errs, fmt.Errorf("%s: %s", prefix, line))
+	}
+	return errors.Join(errs...)
+}
+
+// use returns the steps of the fragment a use step names, with its params
+// filled in.
+func (r *resolver) use(step Step, filled map[string]bool, using []string) ([]Step, error) {
+	if slices.Contains(using, step.Use) {
+		return nil, fmt.Errorf("fragment cycle: %s → %s", strings.Join(using, " → "), step.Use)
+	}
+	fragment, ok := r.fragments[step.Use]
+	if !ok {
+		return nil, errors.New("no such fragment")
+	}
+
+	args := make(map[string]string, len(fragment.Params))
+	var errs []error
+	for _, p := range fragment.Params {
+		value, given := step.With[p.Name]
+		switch {
+		case given:
+			if !strings.Contains(value, "{{") {
+				if err := p.check(value); err != nil {
+					errs = append(errs, fmt.Errorf("param %q: %w", p.Name, err))
+				}
+			}
+		case p.Required:
+			errs = append(errs, fmt.Errorf("param %q is required", p.Name))
+		case p.Default != "":
+			value = p.Default
+		case p.kind() == ParamTypeBool:
+			value = "false"
+		}
+		args[p.Name] = value
+	}
+	for _, name := range slices.Sorted(maps.Keys(step.With)) {
+		if _, ok := args[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown param %q", name))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	steps, err := substitute(fragment.Steps, args)
+	if err != nil {
+		return nil, err
+	}
+	return r.expand(steps, nil, filled, append(using, step.Use))
+}
+
+// substitute returns a copy of steps with the params in args filled in
+// every string value. Placeholders that need other variables are left for
+// the run.
+func substitute(steps []Step, args map[string]string) ([]Step, error) {
+	var node yaml.Node
+	if err := node.Encode(steps); err != nil {
+		return nil, err
+	}
+	parser := template.NewParser()
+	for name, value := range args {
+		parser.Set(name, value)
+	}
+	replaceScalars(&node, parser)
+
+	var out []Step
+	if err := node.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func replaceScalars(n *yaml.Node, parser *template.Parser) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.ShortTag() != "!!str" || !strings.Contains(n.Value, "{{") {
+			return
+		}
+		n.Value = parser.Partial(n.Value)
+	case yaml.MappingNode:
+		// Only values: keys are field names and env var names.
+		for i := 1; i < len(n.Content); i += 2 {
+			replaceScalars(n.Content[i], parser)
+		}
+	default:
+		for _, child := range n.Content {
+			replaceScalars(child, parser)
+		}
+	}
+}
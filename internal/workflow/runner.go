@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Process is a command step ready to run: the prepared command with its
+// shell, working directory and env, and the templated command or script
+// body as written, without the strict-mode prelude.
+type Process struct {
+	Cmd     *exec.Cmd
+	Command string
+	// Interactive processes get the terminal to themselves; Cmd's streams
+	// are left for the runner to attach.
+	Interactive bool
+}
+
+// Runner spawns the processes for command steps. Run returns once the
+// process has exited, and should stop it early when ctx is cancelled.
+type Runner interface {
+	Run(ctx context.Context, p *Process) error
+}
+
+// processRunner runs commands as local processes, handing interactive ones
+// the terminal through the executor's display. Other commands get the
+// terminal too, unless the run view is reading it.
+type processRunner struct {
+	e *Executor
+}
+
+func (r processRunner) Run(ctx context.Context, p *Process) error {
+	if p.Interactive {
+		return r.e.display.exec(p.Cmd, func() error {
+			return r.e.runProcess(ctx, p.Cmd, true)
+		})
+	}
+	return r.e.runProcess(ctx, p.Cmd, !r.e.runView)
+}
@@ -0,0 +1,554 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kevmul/cmdr/internal/styles"
+)
+
+// ─── Run view ─────────────────────────────────────────────────────────────────
+//
+// The run view hosts an entire workflow in one full-screen program: a step
+// list with status icons on the left, a scrolling log of command output on
+// the right, and prompts rendered inline underneath. The executor runs in
+// its own goroutine and talks to the program through messages.
+
+const (
+	runStepsWidth = 36
+	runLogTail    = 20
+)
+
+var errRunViewClosed = errors.New("run view closed")
+
+type stepStatus int
+
+const (
+	stepPending stepStatus = iota
+	stepRunning
+	stepDone
+	stepSkipped
+	stepFailed
+)
+
+func (s stepStatus) icon() string {
+	switch s {
+	case stepRunning:
+		return styles.CursorStyle.Render("●")
+	case stepDone:
+		return styles.SuccessStyle.Render("✔")
+	case stepSkipped:
+		return styles.MutedTextStyle.Render("–")
+	case stepFailed:
+		return styles.ErrorStyle.Render("✖")
+	default:
+		return styles.MutedTextStyle.Render("○")
+	}
+}
+
+type runStep struct {
+	label   string
+	status  stepStatus
+	started time.Time
+	elapsed time.Duration
+}
+
+func (s runStep) duration() time.Duration {
+	if s.status == stepRunning {
+		return time.Since(s.started)
+	}
+	return s.elapsed
+}
+
+type (
+	runStepMsg struct {
+		index  int
+		label  string
+		status stepStatus
+	}
+	runLogMsg    struct{ lines []string }
+	runPromptMsg struct {
+		model promptModel
+		reply chan promptModel
+	}
+	// runPromptDroppedMsg takes down a prompt the run stopped waiting for.
+	runPromptDroppedMsg struct{}
+	runExecMsg          struct {
+		cmd   execCommand
+		reply chan error
+	}
+	runDoneMsg struct{ err error }
+	runTickMsg time.Time
+)
+
+type runModel struct {
+	workflow *Workflow
+	steps    []runStep
+	log      []string
+	viewport viewport.Model
+
+	prompt promptModel
+	reply  chan promptModel
+
+	width  int
+	height int
+
+	complete bool
+	err      error
+
+	// cancel interrupts the run on the first ctrl+c; a second one closes
+	// the view without waiting for cleanup.
+	cancel     func()
+	cancelling bool
+	abandoned  bool
+}
+
+func newRunModel(workflow *Workflow) *runModel {
+	all := workflow.allSteps()
+	steps := make([]runStep, len(all))
+	for i, step := range all {
+		steps[i] = runStep{label: step.Label()}
+	}
+	return &runModel{
+		workflow: workflow,
+		steps:    steps,
+		viewport: viewport.New(0, 0),
+	}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return runTickMsg(t) })
+}
+
+func (m *runModel) Init() tea.Cmd { return tick() }
+
+func (m *runModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.prompt != nil {
+			cmds = append(cmds, m.updatePrompt(m.promptSize()))
+		}
+
+	case tea.KeyMsg:
+		if m.prompt != nil {
+			cmds = append(cmds, m.updatePrompt(msg))
+			break
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			if m.complete || m.cancel == nil {
+				return m, tea.Quit
+			}
+			if m.cancelling {
+				m.abandoned = true
+				return m, tea.Quit
+			}
+			m.cancelling = true
+			m.cancel()
+		case "q", "esc", "enter":
+			if m.complete {
+				return m, tea.Quit
+			}
+		default:
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case runStepMsg:
+		step := &m.steps[msg.index]
+		if msg.label != "" {
+			step.label = msg.label
+		}
+		switch msg.status {
+		case stepRunning:
+			step.started = time.Now()
+		case stepDone, stepFailed:
+			step.elapsed = time.Since(step.started)
+		}
+		step.status = msg.status
+
+	case runLogMsg:
+		m.log = append(m.log, msg.lines...)
+
+	case runPromptMsg:
+		m.prompt = msg.model
+		m.reply = msg.reply
+		cmds = append(cmds, m.prompt.Init(), m.updatePrompt(m.promptSize()))
+
+	case runPromptDroppedMsg:
+		m.prompt, m.reply = nil, nil
+
+	case runExecMsg:
+		reply := msg.reply
+		cmds = append(cmds, tea.Exec(msg.cmd, func(err error) tea.Msg {
+			reply <- err
+			return nil
+		}))
+
+	case runDoneMsg:
+		m.complete = true
+		m.err = msg.err
+
+	case runTickMsg:
+		if !m.complete {
+			cmds = append(cmds, tick())
+		}
+	}
+
+	m.layout()
+	return m, tea.Batch(cmds...)
+}
+
+// updatePrompt forwards a message to the active prompt and hands the final
+// model back to the executor once the prompt is answered or cancelled.
+func (m *runModel) updatePrompt(msg tea.Msg) tea.Cmd {
+	updated, cmd := m.prompt.Update(msg)
+	m.prompt = updated.(promptModel)
+	if m.prompt.finished() {
+		m.reply <- m.prompt
+		m.prompt = nil
+		m.reply = nil
+	}
+	return cmd
+}
+
+// promptSize is the space a prompt gets: the full width and up to half of
+// the screen, leaving the step list and log visible above it.
+func (m *runModel) promptSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: m.width, Height: max(3, m.height/2)}
+}
+
+// layout sizes the log viewport to fill whatever the header, prompt and
+// footer leave over, keeping it pinned to the newest output unless the user
+// has scrolled up.
+func (m *runModel) layout() {
+	atBottom := m.viewport.AtBottom()
+
+	height := m.height - lipgloss.Height(m.headerView()) - lipgloss.Height(m.footerView()) - 2
+	if m.prompt != nil {
+		height -= lipgloss.Height(m.prompt.View())
+	}
+	m.viewport.Width = max(10, m.width-runStepsWidth-2)
+	m.viewport.Height = max(1, height)
+	m.viewport.SetContent(strings.Join(m.log, "\n"))
+
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m *runModel) headerView() string {
+	header := styles.TitleStyle.Render(m.workflow.Name)
+	if m.workflow.Description != "" {
+		header += " " + styles.MutedTextStyle.Render(m.workflow.Description)
+	}
+	return header
+}
+
+func (m *runModel) stepsView() string {
+	// The pane's border and padding take four columns.
+	contentWidth := runStepsWidth - 4
+	labelWidth := contentWidth - 8
+	var sb strings.Builder
+	for i, step := range m.steps {
+		label := lipgloss.NewStyle().MaxWidth(labelWidth).Render(step.label)
+		if step.status == stepRunning {
+			label = styles.SelectedItemStyle.Render(label)
+		}
+
+		elapsed := ""
+		if step.status == stepRunning || step.status == stepDone || step.status == stepFailed {
+			elapsed = formatElapsed(step.duration())
+		}
+
+		line := fmt.Sprintf("%s %s", step.status.icon(), label)
+		gap := max(1, contentWidth-lipgloss.Width(line)-len(elapsed))
+		sb.WriteString(line + strings.Repeat(" ", gap) + styles.MutedTextStyle.Render(elapsed))
+		if i < len(m.steps)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func (m *runModel) footerView() string {
+	switch {
+	case m.complete && m.err != nil:
+		return styles.ErrorStyle.Render("✖ "+m.err.Error()) + styles.MutedTextStyle.Render("  q to exit")
+	case m.complete:
+		return styles.SuccessStyle.Render("✅ Workflow completed successfully!") + styles.MutedTextStyle.Render("  q to exit")
+	case m.cancelling:
+		return styles.InfoStyle.Render("Cancelling…") + styles.MutedTextStyle.Render("  ctrl+c again to quit without cleanup")
+	default:
+		return styles.MutedTextStyle.Render("↑/↓ scroll log  ctrl+c cancel")
+	}
+}
+
+func (m *runModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	paneHeight := m.viewport.Height
+	stepsPane := styles.BlurredInputStyle.
+		Width(runStepsWidth - 2).
+		Height(paneHeight).
+		Render(lipgloss.NewStyle().MaxHeight(paneHeight).Render(m.stepsView()))
+	logPane := styles.BlurredInputStyle.
+		Width(m.viewport.Width).
+		Height(paneHeight).
+		Padding(0).
+		Render(m.viewport.View())
+
+	sections := []string{
+		m.headerView(),
+		lipgloss.JoinHorizontal(lipgloss.Top, stepsPane, logPane),
+	}
+	if m.prompt != nil {
+		sections = append(sections, m.prompt.View())
+	}
+	sections = append(sections, m.footerView())
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// summary renders the step list and, on failure, the tail of the log so the
+// outcome stays on screen after the alternate screen is torn down.
+func (m *runModel) summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\nRan workflow: %s\n", m.workflow.Name)
+	for _, step := range m.steps {
+		elapsed := ""
+		if step.status == stepDone || step.status == stepFailed {
+			elapsed = " " + styles.MutedTextStyle.Render(formatElapsed(step.elapsed))
+		}
+		fmt.Fprintf(&sb, "  %s %s%s\n", step.status.icon(), step.label, elapsed)
+	}
+
+	if m.err != nil && len(m.log) > 0 {
+		start := max(0, len(m.log)-runLogTail)
+		sb.WriteString("\n" + strings.Join(m.log[start:], "\n") + "\n")
+	}
+	return sb.String()
+}
+
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Truncate(time.Second).String()
+}
+
+// ─── Run view display ─────────────────────────────────────────────────────────
+
+type runViewDisplay struct {
+	program *tea.Program
+	log     *logWriter
+	done    chan struct{}
+}
+
+func newRunViewDisplay(program *tea.Program) *runViewDisplay {
+	return &runViewDisplay{
+		program: program,
+		log:     &logWriter{program: program},
+		done:    make(chan struct{}),
+	}
+}
+
+func (d *runViewDisplay) start(workflow *Workflow) {}
+
+func (d *runViewDisplay) stepStarted(index int, label string) {
+	d.program.Send(runStepMsg{index: index, label: label, status: stepRunning})
+}
+
+func (d *runViewDisplay) stepSkipped(index int, label string) {
+	d.program.Send(runStepMsg{index: index, label: label, status: stepSkipped})
+}
+
+func (d *runViewDisplay) stepFinished(index int, err error) {
+	d.log.flush()
+	status := stepDone
+	if err != nil {
+		status = stepFailed
+	}
+	d.program.Send(runStepMsg{index: index, status: status})
+}
+
+func (d *runViewDisplay) finish(err error) {
+	d.log.flush()
+	d.program.Send(runDoneMsg{err: err})
+}
+
+// Commands can't read from the terminal while the run view owns it; steps
+// that need input should be marked interactive.
+func (d *runViewDisplay) stdin() io.Reader  { return nil }
+func (d *runViewDisplay) stdout() io.Writer { return d.log }
+func (d *runViewDisplay) stderr() io.Writer { return d.log }
+
+func (d *runViewDisplay) prompt(ctx context.Context, m promptModel) (promptModel, error) {
+	reply := make(chan promptModel, 1)
+	d.program.Send(runPromptMsg{model: m, reply: reply})
+	select {
+	case result := <-reply:
+		return result, nil
+	case <-ctx.Done():
+		d.program.Send(runPromptDroppedMsg{})
+		return nil, ctx.Err()
+	case <-d.done:
+		return nil, errRunViewClosed
+	}
+}
+
+func (d *runViewDisplay) answered(prompt, answer string) {
+	fmt.Fprintf(d.log, "%s %s\n", styles.MutedTextStyle.Render(prompt+":"), styles.SuccessStyle.Render("✔ "+answer))
+}
+
+// exec suspends the run view and hands the terminal to an interactive
+// command, restoring the view when it exits.
+func (d *runViewDisplay) exec(cmd *exec.Cmd, run func() error) error {
+	reply := make(chan error, 1)
+	d.program.Send(runExecMsg{cmd: execCommand{cmd, run}, reply: reply})
+	select {
+	case err := <-reply:
+		return err
+	case <-d.done:
+		return errRunViewClosed
+	}
+}
+
+// execCommand is a command for tea.Exec that runs through run, which
+// handles its process group and interruption.
+type execCommand struct {
+	cmd *exec.Cmd
+	run func() error
+}
+
+func (c execCommand) Run() error { return c.run() }
+
+func (c execCommand) SetStdin(r io.Reader) {
+	if c.cmd.Stdin == nil {
+		c.cmd.Stdin = r
+	}
+}
+
+func (c execCommand) SetStdout(w io.Writer) {
+	if c.cmd.Stdout == nil {
+		c.cmd.Stdout = w
+	}
+}
+
+func (c execCommand) SetStderr(w io.Writer) {
+	if c.cmd.Stderr == nil {
+		c.cmd.Stderr = w
+	}
+}
+
+// logWriter turns command output into log lines for the run view. Partial
+// lines are buffered until a newline arrives or the step finishes, and
+// carriage-return progress updates keep only their latest state.
+type logWriter struct {
+	mu      sync.Mutex
+	program *tea.Program
+	buf     bytes.Buffer
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	var lines []string
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// No newline yet: put the partial line back for later.
+			w.buf.WriteString(line)
+			break
+		}
+		lines = append(lines, cleanLine(line))
+	}
+	if len(lines) > 0 {
+		w.program.Send(runLogMsg{lines: lines})
+	}
+	return len(p), nil
+}
+
+func (w *logWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() == 0 {
+		return
+	}
+	line := cleanLine(w.buf.String())
+	w.buf.Reset()
+	w.program.Send(runLogMsg{lines: []string{line}})
+}
+
+func cleanLine(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	if i := strings.LastIndex(line, "\r"); i != -1 {
+		line = line[i+1:]
+	}
+	return line
+}
+
+// executeInRunView runs the workflow in a goroutine while the run view owns
+// the terminal, then prints a summary once the view is closed. Closing the
+// view before the run is done interrupts it.
+func (e *Executor) executeInRunView(ctx context.Context, workflow *Workflow) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	interrupt := func() { cancel(&Interrupted{Signal: os.Interrupt}) }
+
+	m := newRunModel(workflow)
+	m.cancel = interrupt
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(),
+		tea.WithInput(e.stdin), tea.WithOutput(e.stdout))
+	d := newRunViewDisplay(p)
+	e.display = d
+
+	finished := make(chan error, 1)
+	go func() { finished <- e.run(ctx, workflow) }()
+
+	result, err := p.Run()
+	close(d.done)
+	interrupt()
+	if err != nil {
+		<-finished
+		return fmt.Errorf("run view failed: %w", err)
+	}
+
+	final := result.(*runModel)
+	var runErr error
+	if final.abandoned {
+		runErr = interruptError(ctx)
+	} else {
+		runErr = <-finished
+	}
+	fmt.Fprint(e.stdout, final.summary())
+	if runErr == nil {
+		fmt.Fprintln(e.stdout, "\n✅ Workflow completed successfully!")
+	}
+	return runErr
+}
@@ -0,0 +1,9 @@
+package workflow
+
+import _ "embed"
+
+// Schema is the JSON Schema for workflow files and bundles, for editors to
+// validate and complete them with.
+//
+//go:embed schema.json
+var Schema []byte
@@ -0,0 +1,197 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Search field weights. A term counts towards a workflow's score once, at
+// the weight of the best field it matched, so name matches rank first.
+const (
+	weightName        = 100
+	weightNamePrefix  = 20
+	weightKey         = 80
+	weightTag         = 60
+	weightNameFuzzy   = 50
+	weightDescription = 40
+	weightStep        = 20
+)
+
+// SearchMatch describes where a query term matched a workflow.
+type SearchMatch struct {
+	Field string // "name", "key", "tag", "description", "prompt", "command" or "script"
+	Step  int    // index of the matching step, or -1 for workflow fields
+	Text  string // full text of the matching field
+	Term  string // the query term that matched
+}
+
+// SearchResult is a workflow that matched every term of a query.
+type SearchResult struct {
+	Index   int // position of the workflow in the searched slice
+	Score   int
+	Matches []SearchMatch
+
+	// NameIndexes are the rune positions in the name that matched, for
+	// highlighting.
+	NameIndexes []int
+}
+
+// Search finds the workflows matching a query. Words match
+// case-insensitively against the name, key, tags, description and each
+// step's prompt, command, script and description; #tag terms must match the start
+// of one of the workflow's tags. Every term has to match somewhere.
+// Results are ordered by score, then by their original order.
+func Search(workflows []Workflow, query string) []SearchResult {
+	var tags, words []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(field, "#") && len(field) > 1 {
+			tags = append(tags, field[1:])
+		} else {
+			words = append(words, field)
+		}
+	}
+
+	var results []SearchResult
+	for i := range workflows {
+		if result, ok := searchWorkflow(&workflows[i], tags, words); ok {
+			result.Index = i
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+func searchWorkflow(wf *Workflow, tags, words []string) (SearchResult, bool) {
+	var result SearchResult
+
+	for _, want := range tags {
+		match, ok := matchTag(wf, want)
+		if !ok {
+			return result, false
+		}
+		result.Score += weightTag
+		result.Matches = append(result.Matches, match)
+	}
+
+	for _, word := range words {
+		match, score, nameIndexes, ok := matchWord(wf, word)
+		if !ok {
+			return result, false
+		}
+		result.Score += score
+		result.Matches = append(result.Matches, match)
+		result.NameIndexes = append(result.NameIndexes, nameIndexes...)
+	}
+
+	return result, true
+}
+
+func matchTag(wf *Workflow, want string) (SearchMatch, bool) {
+	for _, tag := range wf.Tags {
+		if strings.HasPrefix(strings.ToLower(tag), want) {
+			return SearchMatch{Field: "tag", Step: -1, Text: tag, Term: "#" + want}, true
+		}
+	}
+	return SearchMatch{}, false
+}
+
+// matchWord finds the best field for a single lowercase word.
+func matchWord(wf *Workflow, word string) (SearchMatch, int, []int, bool) {
+	field := func(name, text string) SearchMatch {
+		return SearchMatch{Field: name, Step: -1, Text: text, Term: word}
+	}
+
+	lowerName := strings.ToLower(wf.Name)
+	if pos := strings.Index(lowerName, word); pos != -1 {
+		score := weightName
+		if pos == 0 {
+			score += weightNamePrefix
+		}
+		start := len([]rune(lowerName[:pos]))
+		indexes := make([]int, len([]rune(word)))
+		for i := range indexes {
+			indexes[i] = start + i
+		}
+		return field("name", wf.Name), score, indexes, true
+	}
+
+	if strings.Contains(strings.ToLower(wf.Key), word) {
+		return field("key", wf.Key), weightKey, nil, true
+	}
+
+	for _, tag := range wf.Tags {
+		if strings.Contains(strings.ToLower(tag), word) {
+			return field("tag", tag), weightTag, nil, true
+		}
+	}
+
+	if matches := fuzzy.Find(word, []string{wf.Name}); len(matches) > 0 {
+		return field("name", wf.Name), weightNameFuzzy, runeIndexes(wf.Name, matches[0].MatchedIndexes), true
+	}
+
+	if strings.Contains(strings.ToLower(wf.Description), word) {
+		return field("description", wf.Description), weightDescription, nil, true
+	}
+
+	for i, step := range wf.Steps {
+		for _, candidate := range []struct{ name, text string }{
+			{"command", step.Command},
+			{"script", step.Script},
+			{"prompt", step.Prompt},
+			{"description", step.Description},
+		} {
+			if candidate.text != "" && strings.Contains(strings.ToLower(candidate.text), word) {
+				match := field(candidate.name, candidate.text)
+				match.Step = i
+				return match, weightStep, nil, true
+			}
+		}
+	}
+
+	return SearchMatch{}, 0, nil, false
+}
+
+// Where describes the matched field, e.g. "key" or "step 3 command".
+func (m SearchMatch) Where() string {
+	if m.Step >= 0 {
+		return fmt.Sprintf("step %d %s", m.Step+1, m.Field)
+	}
+	return m.Field
+}
+
+// Line returns the line of the matched text that contains the term, so a
+// match inside a multi-line script shows just the relevant line.
+func (m SearchMatch) Line() string {
+	lines := strings.Split(m.Text, "\n")
+	term := strings.ToLower(strings.TrimPrefix(m.Term, "#"))
+	for _, line := range lines {
+		if strings.Contains(strings.ToLower(line), term) {
+			return strings.TrimSpace(line)
+		}
+	}
+	return strings.TrimSpace(lines[0])
+}
+
+// runeIndexes converts byte offsets into s to rune positions.
+func runeIndexes(s string, byteIndexes []int) []int {
+	positions := make(map[int]int, len(s))
+	n := 0
+	for i := range s {
+		positions[i] = n
+		n++
+	}
+	result := make([]int, 0, len(byteIndexes))
+	for _, b := range byteIndexes {
+		if r, ok := positions[b]; ok {
+			result = append(result, r)
+		}
+	}
+	return result
+}
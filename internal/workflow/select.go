@@ -1,109 +1,293 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kevmul/cmdr/internal/styles"
+	"github.com/sahilm/fuzzy"
 )
 
 // ─── Select ───────────────────────────────────────────────────────────────────
 
+const selectMaxVisible = 10
+
 type selectModel struct {
-	prompt   string
-	options  []SelectOption
-	cursor   int
-	selected SelectOption
-	done     bool
+	prompt    string
+	helpText  string
+	options   []SelectOption
+	filter    string
+	matches   []selectMatch
+	cursor    int
+	offset    int
+	height    int
+	selected  SelectOption
+	done      bool
+	cancelled bool
+}
+
+// selectMatch is an option that passed the current filter, along with the
+// byte offsets of the characters that matched for highlighting.
+type selectMatch struct {
+	index   int
+	matched map[int]bool
 }
 
 type keyMap struct {
-	Up   key.Binding
-	Down key.Binding
-	Run  key.Binding
-	Quit key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Home     key.Binding
+	End      key.Binding
+	Clear    key.Binding
+	Run      key.Binding
+	Quit     key.Binding
 }
 
 var keys = keyMap{
 	Up: key.NewBinding(
-		key.WithKeys("k", "up"),
-		key.WithHelp("k, up", "Move up"),
+		key.WithKeys("up", "ctrl+k", "ctrl+p"),
+		key.WithHelp("↑, ctrl+k", "Move up"),
 	),
 	Down: key.NewBinding(
-		key.WithKeys("j", "down"),
-		key.WithHelp("j, down", "Move down"),
+		key.WithKeys("down", "ctrl+j", "ctrl+n"),
+		key.WithHelp("↓, ctrl+j", "Move down"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "Page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdn", "Page down"),
+	),
+	Home: key.NewBinding(
+		key.WithKeys("home"),
+		key.WithHelp("home", "First option"),
+	),
+	End: key.NewBinding(
+		key.WithKeys("end"),
+		key.WithHelp("end", "Last option"),
+	),
+	Clear: key.NewBinding(
+		key.WithKeys("ctrl+u"),
+		key.WithHelp("ctrl+u", "Clear filter"),
 	),
 	Run: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("↵", "run"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q, ctrl+c", "quit"),
+		key.WithKeys("esc", "ctrl+c"),
+		key.WithHelp("esc, ctrl+c", "quit"),
 	),
 }
 
+// optionSource adapts a slice of options for fuzzy matching on their text.
+type optionSource []SelectOption
+
+func (s optionSource) String(i int) string { return s[i].Text }
+func (s optionSource) Len() int            { return len(s) }
+
+func newSelectModel(prompt, helpText string, options []SelectOption, defaultValue string) selectModel {
+	m := selectModel{
+		prompt:   prompt,
+		helpText: helpText,
+		options:  options,
+		height:   selectMaxVisible,
+	}
+	m.applyFilter()
+
+	if i := defaultIndex(options, defaultValue); i >= 0 {
+		m.cursor = i
+	}
+	m.scroll()
+	return m
+}
+
+// defaultIndex finds the option a default names, matching values before
+// texts, or -1.
+func defaultIndex(options []SelectOption, defaultValue string) int {
+	if defaultValue == "" {
+		return -1
+	}
+	for i, opt := range options {
+		if opt.OptionValue() == defaultValue {
+			return i
+		}
+	}
+	for i, opt := range options {
+		if opt.Text == defaultValue {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyFilter recomputes the visible options from the filter text. An empty
+// filter keeps every option in its original order; otherwise options are
+// ranked by fuzzy match score.
+func (m *selectModel) applyFilter() {
+	m.matches = m.matches[:0]
+	m.cursor = 0
+	m.offset = 0
+
+	if m.filter == "" {
+		for i := range m.options {
+			m.matches = append(m.matches, selectMatch{index: i})
+		}
+		return
+	}
+
+	for _, match := range fuzzy.FindFrom(m.filter, optionSource(m.options)) {
+		matched := make(map[int]bool, len(match.MatchedIndexes))
+		for _, idx := range match.MatchedIndexes {
+			matched[idx] = true
+		}
+		m.matches = append(m.matches, selectMatch{index: match.Index, matched: matched})
+	}
+}
+
+func (m *selectModel) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.matches)-1))
+	m.scroll()
+}
+
+func (m *selectModel) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
 func (m selectModel) Init() tea.Cmd { return nil }
 
+func (m selectModel) finished() bool { return m.done || m.cancelled }
+
 func (m selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the prompt, help text, filter line and status.
+		m.height = max(1, min(selectMaxVisible, msg.Height-5))
+		m.scroll()
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, keys.Up):
-			if m.cursor > 0 {
-				m.cursor--
-			}
+			m.move(-1)
 		case key.Matches(msg, keys.Down):
-			if m.cursor < len(m.options)-1 {
-				m.cursor++
-			}
+			m.move(1)
+		case key.Matches(msg, keys.PageUp):
+			m.move(-m.height)
+		case key.Matches(msg, keys.PageDown):
+			m.move(m.height)
+		case key.Matches(msg, keys.Home):
+			m.move(-len(m.matches))
+		case key.Matches(msg, keys.End):
+			m.move(len(m.matches))
+		case key.Matches(msg, keys.Clear):
+			m.filter = ""
+			m.applyFilter()
 		case key.Matches(msg, keys.Run):
-			m.selected = m.options[m.cursor]
+			if len(m.matches) == 0 {
+				return m, nil
+			}
+			m.selected = m.options[m.matches[m.cursor].index]
 			m.done = true
-			return m, tea.Quit
 		case key.Matches(msg, keys.Quit):
-			return m, tea.Quit
+			m.cancelled = true
+		case msg.Type == tea.KeyBackspace:
+			if len(m.filter) > 0 {
+				runes := []rune(m.filter)
+				m.filter = string(runes[:len(runes)-1])
+				m.applyFilter()
+			}
+		case msg.Type == tea.KeyRunes, msg.Type == tea.KeySpace:
+			m.filter += string(msg.Runes)
+			m.applyFilter()
 		}
 	}
 	return m, nil
 }
 
+// highlight renders text with the fuzzy-matched characters emphasised.
+func highlight(text string, matched map[int]bool, base func(...string) string) string {
+	if len(matched) == 0 {
+		return base(text)
+	}
+
+	var sb strings.Builder
+	for i, r := range text {
+		if matched[i] {
+			sb.WriteString(styles.CursorStyle.Render(string(r)))
+		} else {
+			sb.WriteString(base(string(r)))
+		}
+	}
+	return sb.String()
+}
+
 func (m selectModel) View() string {
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "%s:\n", m.prompt)
-	for i, opt := range m.options {
+	if m.helpText != "" {
+		sb.WriteString(styles.HelpTextStyle.Render(m.helpText) + "\n")
+	}
+
+	if m.filter != "" {
+		fmt.Fprintf(&sb, "  %s %s\n", styles.CursorStyle.Render("/"), m.filter)
+	} else {
+		sb.WriteString(styles.MutedTextStyle.Render("  type to filter") + "\n")
+	}
+
+	if len(m.matches) == 0 {
+		sb.WriteString(styles.MutedTextStyle.Render("    (no matches)") + "\n")
+	}
+
+	end := min(m.offset+m.height, len(m.matches))
+	for i := m.offset; i < end; i++ {
+		match := m.matches[i]
+		opt := m.options[match.index]
+
+		desc := ""
+		if opt.Description != "" {
+			desc = " " + styles.MutedTextStyle.Render(opt.Description)
+		}
+
 		if i == m.cursor {
 			cursor := styles.CursorStyle.Render("▶")
-			fmt.Fprintf(&sb, "  %s %s\n", cursor, styles.SelectedItemStyle.Render(opt.Text))
+			text := highlight(opt.Text, match.matched, styles.SelectedItemStyle.Render)
+			fmt.Fprintf(&sb, "  %s %s%s\n", cursor, text, desc)
 		} else {
-			fmt.Fprintf(&sb, "    %s\n", opt.Text)
+			text := highlight(opt.Text, match.matched, styles.NormalItemStyle.Render)
+			fmt.Fprintf(&sb, "    %s%s\n", text, desc)
 		}
 	}
+
+	if len(m.matches) > m.height {
+		status := fmt.Sprintf("  %d/%d", m.cursor+1, len(m.matches))
+		if len(m.matches) != len(m.options) {
+			status += fmt.Sprintf(" (of %d)", len(m.options))
+		}
+		sb.WriteString(styles.MutedTextStyle.Render(status) + "\n")
+	}
 	return sb.String()
 }
 
-func (e *Executor) executeSelect(step Step) error {
-	prompt := e.parser.Parse(step.Prompt)
-
-	m := selectModel{
-		prompt:  prompt,
-		options: step.Options,
-	}
-	p := tea.NewProgram(m)
+func (e *Executor) executeSelect(ctx context.Context, step Step) error {
+	step = e.templated(step)
 
-	result, err := p.Run()
+	selected, err := e.prompter.Select(ctx, step)
 	if err != nil {
-		return fmt.Errorf("select failed: %w", err)
-	}
-
-	final := result.(selectModel)
-	if !final.done {
-		return fmt.Errorf("selection cancelled")
+		return err
 	}
 
-	fmt.Printf("\n  ✔  %s\n\n", final.selected.Text)
-	e.parser.Set(step.Variable, final.selected.Value)
+	e.answered(step, step.Prompt, selected.Text, selected.OptionValue())
+	e.parser.Set(step.Variable, selected.OptionValue())
 	return nil
 }
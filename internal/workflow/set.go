@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ─── Set ──────────────────────────────────────────────────────────────────────
+
+// Assignment sets a variable to a templated value.
+type Assignment struct {
+	Name  string
+	Value string
+}
+
+// Assignments are the values of a set step. In YAML they're a mapping of
+// variable names to templates, applied in the order written, so later
+// values can use earlier ones.
+type Assignments []Assignment
+
+// UnmarshalYAML reads a mapping, keeping its order.
+func (a *Assignments) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: values must be a mapping of variable names to values", value.Line)
+	}
+	out := make(Assignments, 0, len(value.Content)/2)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+		if val.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: value of %q must be a string", val.Line, key.Value)
+		}
+		out = append(out, Assignment{Name: key.Value, Value: val.Value})
+	}
+	*a = out
+	return nil
+}
+
+// MarshalYAML writes the assignments back as a mapping.
+func (a Assignments) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, assignment := range a {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: assignment.Name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: assignment.Value},
+		)
+	}
+	return node, nil
+}
+
+// names returns the variables assigned, in order.
+func (a Assignments) names() []string {
+	names := make([]string, len(a))
+	for i, assignment := range a {
+		names[i] = assignment.Name
+	}
+	return names
+}
+
+func (e *Executor) executeSet(step Step) error {
+	for _, assignment := range step.Values {
+		value, err := e.parser.Execute(assignment.Value)
+		if err != nil {
+			return fmt.Errorf("set %s: %w", assignment.Name, err)
+		}
+		e.parser.Set(assignment.Name, value)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Shell is the interpreter a command step runs in. In YAML it is either a
+// name such as "bash" or "python3", or a full argv such as
+// ["bash", "--noprofile", "-c"]. A custom argv receives the command, or the
+// path of the script file, as its last argument.
+type Shell []string
+
+// UnmarshalYAML accepts a single name as well as a list.
+func (s *Shell) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = strings.Fields(value.Value)
+		return nil
+	}
+	var argv []string
+	if err := value.Decode(&argv); err != nil {
+		return err
+	}
+	*s = argv
+	return nil
+}
+
+// MarshalYAML writes a bare name back as a scalar.
+func (s Shell) MarshalYAML() (any, error) {
+	if len(s) == 1 {
+		return s[0], nil
+	}
+	return []string(s), nil
+}
+
+func (s Shell) String() string { return strings.Join(s, " ") }
+
+// name is the interpreter's base name, e.g. "bash" for /bin/bash.
+func (s Shell) name() string {
+	if len(s) == 0 {
+		return "sh"
+	}
+	return filepath.Base(s[0])
+}
+
+// posix reports whether the shell understands `set -e` and friends.
+func (s Shell) posix() bool {
+	switch s.name() {
+	case "sh", "bash", "zsh", "dash", "ksh":
+		return true
+	}
+	return false
+}
+
+// inlineFlags are the flags each known interpreter takes before an inline
+// program.
+var inlineFlags = map[string]string{
+	"sh":      "-c",
+	"bash":    "-c",
+	"zsh":     "-c",
+	"dash":    "-c",
+	"ksh":     "-c",
+	"fish":    "-c",
+	"python":  "-c",
+	"python3": "-c",
+	"node":    "-e",
+	"ruby":    "-e",
+	"perl":    "-e",
+	"pwsh":    "-Command",
+}
+
+// argv builds the arguments for running an inline command or, when
+// scriptPath is set, a script file.
+func (s Shell) argv(command, scriptPath string) []string {
+	if len(s) == 0 {
+		s = Shell{"sh"}
+	}
+	args := append([]string(nil), s...)
+
+	if len(s) > 1 {
+		// A custom argv already carries its flags.
+		if scriptPath != "" {
+			return append(args, scriptPath)
+		}
+		return append(args, command)
+	}
+
+	if scriptPath != "" {
+		return append(args, scriptPath)
+	}
+	flag, ok := inlineFlags[s.name()]
+	if !ok {
+		flag = "-c"
+	}
+	return append(args, flag, command)
+}
+
+// strictPrelude is prepended to POSIX commands in strict mode. sh may not
+// support pipefail, so it is only tried there.
+func (s Shell) strictPrelude() string {
+	if s.name() == "sh" || s.name() == "dash" {
+		return "set -eu\n(set -o pipefail) 2>/dev/null && set -o pipefail\n"
+	}
+	return "set -euo pipefail\n"
+}
+
+// shellFor returns the shell a step runs in: its own, the workflow's, or sh.
+func shellFor(wf *Workflow, step Step) Shell {
+	switch {
+	case len(step.Shell) > 0:
+		return step.Shell
+	case len(wf.Shell) > 0:
+		return wf.Shell
+	default:
+		return Shell{"sh"}
+	}
+}
+
+// strictFor reports whether strict mode applies to a step. The step's
+// setting overrides the workflow's.
+func strictFor(wf *Workflow, step Step) bool {
+	if step.Strict != nil {
+		return *step.Strict
+	}
+	return wf.Strict
+}
+
+// writeScript writes a script body to a temp file and returns its path.
+func writeScript(body string) (string, error) {
+	f, err := os.CreateTemp("", "cmdr-script-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create script file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(body); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write script file: %w", err)
+	}
+	return f.Name(), nil
+}
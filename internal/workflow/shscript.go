@@ -0,0 +1,667 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/template"
+	"gopkg.in/yaml.v3"
+)
+
+// ─── Shell script export ──────────────────────────────────────────────────────
+
+// templateVar matches a placeholder that is just a variable. Expressions
+// that call functions have no shell equivalent and are left as text.
+var templateVar = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.-]*)\s*\}\}`)
+
+var templatePlaceholder = regexp.MustCompile(`\{\{[^{}]+\}\}`)
+
+// WriteShellScript writes a bash script that runs the workflow without
+// cmdr: params become arguments and flags, prompts become read and select,
+// conditions become if blocks and finally steps an EXIT trap. Anything the
+// script can't reproduce faithfully is listed in its header; the same
+// warnings are returned.
+func (w *Workflow) WriteShellScript(out io.Writer) ([]string, error) {
+	s := &scriptWriter{wf: w, total: len(w.allSteps()), params: make(map[string]bool)}
+	for _, p := range w.Params {
+		s.params[p.Name] = true
+	}
+
+	var body strings.Builder
+	s.out = &body
+	s.writeMain()
+
+	var head strings.Builder
+	s.out = &head
+	s.writeHeader()
+
+	_, err := io.WriteString(out, head.String()+body.String())
+	return s.warnings, err
+}
+
+type scriptWriter struct {
+	wf       *Workflow
+	out      *strings.Builder
+	indent   int
+	total    int
+	params   map[string]bool
+	warnings []string
+
+	// capturesEnv is set when a step needs __cmdr_exports.
+	capturesEnv bool
+}
+
+func (s *scriptWriter) line(format string, args ...any) {
+	if format == "" {
+		s.out.WriteString("\n")
+		return
+	}
+	s.out.WriteString(strings.Repeat("\t", s.indent) + fmt.Sprintf(format, args...) + "\n")
+}
+
+// say prints a quoted word, which may start with a dash.
+func (s *scriptWriter) say(word string) {
+	s.line(`printf '%%s\n' %s`, word)
+}
+
+func (s *scriptWriter) warn(format string, args ...any) {
+	s.warnings = append(s.warnings, fmt.Sprintf(format, args...))
+}
+
+func (s *scriptWriter) writeHeader() {
+	w := s.wf
+	s.line("#!/usr/bin/env bash")
+	s.line("# %s (%s), exported from cmdr.", w.Name, w.Key)
+	if w.Description != "" {
+		s.line("# %s", w.Description)
+	}
+	s.line("#")
+	s.line("# %s", firstLine(s.usage()))
+	if len(s.warnings) > 0 {
+		s.line("#")
+		s.line("# Warnings:")
+		for _, warning := range s.warnings {
+			s.line("#   - %s", warning)
+		}
+	}
+	s.line("")
+	s.line("set -e")
+	s.line("")
+	s.line(`__cmdr_die() { echo "Error: $*" >&2; exit 1; }`)
+	s.line("")
+	if s.capturesEnv {
+		s.out.WriteString(exportsHelper)
+		s.line("")
+	}
+}
+
+// exportsHelper reads KEY=VALUE lines the way WorkflowEnv.ParseAndApply
+// does and prints them as export commands, quoted for eval.
+const exportsHelper = `__cmdr_exports() {
+	local line key value
+	while IFS= read -r line || [ -n "$line" ]; do
+		case "${line##* }" in *=*) line=${line##* } ;; esac
+		line=${line#"${line%%[![:space:]]*}"}
+		line=${line%"${line##*[![:space:]]}"}
+		line=${line#export }
+		case "$line" in *=*) ;; *) continue ;; esac
+		key=${line%%=*}
+		value=${line#*=}
+		case "$key" in ''|[0-9]*|*[!A-Za-z0-9_]*) continue ;; esac
+		case "$value" in \"*\"|\'*\') value=${value:1:${#value}-2} ;; esac
+		printf 'export %s=%q\n' "$key" "$value"
+	done
+}
+`
+
+func (s *scriptWriter) hasFlags() bool {
+	return len(s.wf.Params) > len(s.wf.Positionals())
+}
+
+func (s *scriptWriter) writeMain() {
+	w := s.wf
+	s.writeParams()
+	s.writeVars()
+	s.writeBuiltins()
+
+	if w.Cd != "" {
+		s.warn("cd %s: a script can't change its caller's directory", w.Cd)
+	}
+
+	if len(w.Finally) > 0 {
+		s.line("__cmdr_finally() {")
+		s.indent++
+		s.line("local status=$?")
+		s.line("trap - EXIT")
+		s.writeSteps(w.Finally, len(w.Steps))
+		s.line(`[ "$status" -ne 0 ] || echo "✅ Workflow completed successfully!"`)
+		s.line(`exit "$status"`)
+		s.indent--
+		s.line("}")
+		s.line("trap __cmdr_finally EXIT")
+		s.line("")
+	}
+
+	s.line("echo %s", quotePOSIX("Running workflow: "+w.Name))
+	s.line("echo")
+	s.writeSteps(w.Steps, 0)
+	if len(w.Finally) == 0 {
+		s.line(`echo "✅ Workflow completed successfully!"`)
+	}
+}
+
+// builtinShell are the shell equivalents of the built-in variables.
+var builtinShell = map[string]string{
+	"git.branch":    "$(git rev-parse --abbrev-ref HEAD 2>/dev/null)",
+	"git.sha":       "$(git rev-parse HEAD 2>/dev/null)",
+	"git.short_sha": "$(git rev-parse --short HEAD 2>/dev/null)",
+	"git.root":      "$(git rev-parse --show-toplevel 2>/dev/null)",
+	"git.dirty":     `$(s=$(git status --porcelain 2>/dev/null) && { [ -n "$s" ] && echo true || echo false; })`,
+	"cwd":           "$PWD",
+	"user":          "${USER:-$(id -un)}",
+	"hostname":      "$(hostname)",
+	"os":            "$(uname -s | tr '[:upper:]' '[:lower:]')",
+	"arch":          "$(uname -m | sed 's/x86_64/amd64/;s/aarch64/arm64/')",
+	"now":           "$(date +%Y-%m-%dT%H:%M:%S%z)",
+}
+
+// writeBuiltins sets the built-in variables the workflow uses, unless a
+// param or var already did. Unlike under cmdr, the git ones aren't updated
+// after commands.
+func (s *scriptWriter) writeBuiltins() {
+	data, err := yaml.Marshal(s.wf)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, m := range templateVar.FindAllStringSubmatch(string(data), -1) {
+		names = append(names, m[1])
+	}
+	for _, step := range s.wf.allSteps() {
+		if step.Condition != nil {
+			names = append(names, step.Condition.Variable)
+		}
+	}
+	sort.Strings(names)
+
+	wrote := false
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		value, ok := builtinShell[name]
+		switch {
+		case ok:
+		case name == "workflow.key":
+			value = escapeDoubleLiteral(s.wf.Key)
+		case name == "workflow.name":
+			value = escapeDoubleLiteral(s.wf.Name)
+		default:
+			continue
+		}
+		s.line(`: "${%s=%s}"`, shellVar(name), value)
+		wrote = true
+	}
+	if wrote {
+		s.line("")
+	}
+}
+
+// ─── Params ───────────────────────────────────────────────────────────────────
+
+// writeParams parses the script's arguments into the param variables. A
+// param that wasn't given and has no default stays unset, so prompts for
+// it still ask, as they do under cmdr.
+func (s *scriptWriter) writeParams() {
+	w := s.wf
+	if len(w.Params) == 0 {
+		return
+	}
+
+	s.line("__cmdr_usage() {")
+	s.indent++
+	s.line("cat >&2 <<-'CMDR_USAGE'")
+	s.out.WriteString(s.usage())
+	s.line("CMDR_USAGE")
+	s.line("exit 2")
+	s.indent--
+	s.line("}")
+	s.line("")
+
+	s.line("__cmdr_args=()")
+	s.line(`while [ $# -gt 0 ]; do`)
+	s.indent++
+	s.line(`case "$1" in`)
+	s.indent++
+	for _, p := range w.Params {
+		if p.Positional {
+			continue
+		}
+		name := shellVar(p.Name)
+		patterns := "--" + p.Name
+		if p.Short != "" {
+			patterns = "-" + p.Short + "|" + patterns
+		}
+		if p.kind() == ParamTypeBool {
+			s.line("%s) %s=true; shift ;;", patterns, name)
+			s.line("--%s=*) %s=${1#*=}; shift ;;", p.Name, name)
+			continue
+		}
+		s.line(`%s) [ $# -ge 2 ] || __cmdr_usage; %s=$2; shift 2 ;;`, patterns, name)
+		s.line("--%s=*) %s=${1#*=}; shift ;;", p.Name, name)
+	}
+	s.line("-h|--help) __cmdr_usage ;;")
+	s.line("--) shift; __cmdr_args+=(\"$@\"); break ;;")
+	s.line(`-*) echo "unknown flag: $1" >&2; __cmdr_usage ;;`)
+	s.line(`*) __cmdr_args+=("$1"); shift ;;`)
+	s.indent--
+	s.line("esac")
+	s.indent--
+	s.line("done")
+	s.line(`set -- ${__cmdr_args[@]+"${__cmdr_args[@]}"}`)
+
+	positionals := w.Positionals()
+	variadic := false
+	for i, p := range positionals {
+		if p.Variadic {
+			s.line(`[ $# -lt %d ] || %s="${*:%d}"`, i+1, shellVar(p.Name), i+1)
+			variadic = true
+			continue
+		}
+		s.line(`[ $# -lt %d ] || %s=$%d`, i+1, shellVar(p.Name), i+1)
+	}
+	if !variadic {
+		s.line(`[ $# -le %d ] || { echo "too many arguments" >&2; __cmdr_usage; }`, len(positionals))
+	}
+
+	for _, p := range w.Params {
+		name := shellVar(p.Name)
+		switch {
+		case p.Required:
+			s.line(`[ -n "${%s+x}" ] || { echo "missing %s" >&2; __cmdr_usage; }`, name, p.Name)
+		case p.Default != "":
+			s.line(`: "${%s=%s}"`, name, escapeDouble(p.Default))
+		case p.kind() == ParamTypeBool:
+			s.line(`: "${%s=false}"`, name)
+		case p.Variadic:
+			s.line(`: "${%s=}"`, name)
+		}
+		if p.kind() == ParamTypeInt {
+			s.line(`case "${%s-0}" in ''|-|*[!0-9-]*|?*-*) __cmdr_die "%s must be a whole number" ;; esac`, name, p.Name)
+		}
+	}
+	s.line("")
+}
+
+// writeVars sets the workflow's vars. Params of the same name win, as they
+// do under cmdr, so for those a var only fills in when it wasn't given.
+func (s *scriptWriter) writeVars() {
+	if len(s.wf.Vars) == 0 {
+		return
+	}
+	for _, name := range sortedKeys(s.wf.Vars) {
+		value := escapeDoubleLiteral(s.wf.Vars[name])
+		if s.params[name] {
+			s.line(`: "${%s=%s}"`, shellVar(name), value)
+		} else {
+			s.line(`%s="%s"`, shellVar(name), value)
+		}
+	}
+	s.line("")
+}
+
+func (s *scriptWriter) usage() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Usage: %s.sh%s", s.wf.Key, s.wf.ArgsUsage())
+	if s.hasFlags() {
+		sb.WriteString(" [flags]")
+	}
+	sb.WriteString("\n")
+
+	for _, p := range s.wf.Params {
+		flag := p.Name
+		if !p.Positional {
+			flag = "--" + p.Name
+			if p.kind() != ParamTypeBool {
+				flag += " VALUE"
+			}
+			if p.Short != "" {
+				flag = "-" + p.Short + ", " + flag
+			}
+		}
+		help := p.Help
+		if p.Default != "" {
+			help = strings.TrimSpace(help + fmt.Sprintf(" (default %s)", p.Default))
+		}
+		sb.WriteString(strings.TrimRight(fmt.Sprintf("  %-20s %s", flag, help), " ") + "\n")
+	}
+	return sb.String()
+}
+
+// ─── Steps ────────────────────────────────────────────────────────────────────
+
+func (s *scriptWriter) writeSteps(steps []Step, offset int) {
+	for i, step := range steps {
+		n := offset + i + 1
+		s.line("# Step %d: %s", n, firstLine(step.Label()))
+
+		if c := step.Condition; c != nil {
+			s.line("if %s; then", conditionTest(c))
+			s.indent++
+			s.writeStep(step, n)
+			s.indent--
+			s.line("else")
+			s.line("\techo %s", quotePOSIX(fmt.Sprintf("Skipping step %d/%d (condition not met)", n, s.total)))
+			s.line("fi")
+		} else {
+			s.writeStep(step, n)
+		}
+		s.line("")
+	}
+}
+
+func conditionTest(c *Condition) string {
+	v := "${" + shellName(c.Variable) + "-}"
+	switch c.Operator {
+	case "equals":
+		return fmt.Sprintf(`[ "%s" = %s ]`, v, quotePOSIX(c.Value))
+	case "not_equals":
+		return fmt.Sprintf(`[ "%s" != %s ]`, v, quotePOSIX(c.Value))
+	case "empty":
+		return fmt.Sprintf(`[ -z "%s" ]`, v)
+	case "not_empty":
+		return fmt.Sprintf(`[ -n "%s" ]`, v)
+	default:
+		return "true"
+	}
+}
+
+func (s *scriptWriter) writeStep(step Step, n int) {
+	s.warnExpressions(step, n)
+	switch step.Type {
+	case StepTypeMessage:
+		s.say(s.word(step.Prompt))
+	case StepTypeInput, StepTypeSelect, StepTypeConfirm, StepTypeFile:
+		s.writePrompt(step, n)
+	case StepTypeCommand:
+		s.writeCommand(step, n)
+	case StepTypeSet:
+		for _, a := range step.Values {
+			s.line(`%s="%s"`, shellVar(a.Name), escapeDouble(a.Value))
+		}
+	}
+}
+
+// warnExpressions warns about placeholders in a step that call template
+// functions, which the script leaves as text.
+func (s *scriptWriter) warnExpressions(step Step, n int) {
+	data, err := yaml.Marshal(step)
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, p := range templatePlaceholder.FindAllString(string(data), -1) {
+		if seen[p] || templateVar.MatchString(p) || template.Check(p) != nil {
+			continue
+		}
+		seen[p] = true
+		s.warn("step %d: %s uses template functions, which scripts can't evaluate; left as text", n, p)
+	}
+}
+
+// writePrompt asks for a variable, unless it's a param that was given on
+// the command line.
+func (s *scriptWriter) writePrompt(step Step, n int) {
+	name := shellVar(step.Variable)
+	guarded := s.params[step.Variable]
+	if guarded {
+		s.line(`if [ -z "${%s+x}" ]; then`, name)
+		s.indent++
+	}
+
+	if step.HelpText != "" {
+		s.say(s.word(step.HelpText))
+	}
+	switch step.Type {
+	case StepTypeInput:
+		s.line(`read -r -p %s %s || __cmdr_die "input cancelled"`, s.word(step.Prompt+": "), name)
+
+	case StepTypeSelect:
+		if step.Default != "" {
+			s.warn("step %d: select menus can't preselect %q", n, step.Default)
+		}
+		values := make([]string, len(step.Options))
+		texts := make([]string, len(step.Options))
+		for i, opt := range step.Options {
+			values[i] = s.word(opt.OptionValue())
+			texts[i] = s.word(opt.Text)
+		}
+		s.say(s.word(step.Prompt))
+		s.line("PS3='> '")
+		s.line("__cmdr_values=(%s)", strings.Join(values, " "))
+		s.line("__cmdr_choice=")
+		s.line("select __cmdr_choice in %s; do", strings.Join(texts, " "))
+		s.line(`	[ -z "$__cmdr_choice" ] || { %s=${__cmdr_values[REPLY-1]}; break; }`, name)
+		s.line("done")
+		s.line(`[ -n "$__cmdr_choice" ] || __cmdr_die "selection cancelled"`)
+
+	case StepTypeConfirm:
+		s.line(`read -r -p %s __cmdr_answer || __cmdr_die "confirm cancelled"`, s.word(step.Prompt+" [y/N] "))
+		s.line(`case "$__cmdr_answer" in [yY]*) %s=true ;; *) %s=false ;; esac`, name, name)
+
+	case StepTypeFile:
+		s.warn("step %d: the file picker becomes a plain path prompt, without its root, filters or checks", n)
+		s.line(`read -e -r -p %s %s || __cmdr_die "file selection cancelled"`, s.word(step.Prompt+": "), name)
+	}
+
+	if guarded {
+		s.indent--
+		s.line("fi")
+	}
+}
+
+func (s *scriptWriter) writeCommand(step Step, n int) {
+	header := fmt.Sprintf("[%d/%d] ", n, s.total)
+	switch {
+	case step.Description != "":
+		s.say(s.word(header + step.Description))
+	case step.Script != "":
+		s.say(quotePOSIX(fmt.Sprintf("%sRunning script (%s)", header, shellFor(s.wf, step))))
+	default:
+		s.say(s.word(header + "Running: " + step.Command))
+	}
+
+	if step.Interactive && (step.CaptureOutput || step.CaptureEnv) {
+		s.warn("step %d: interactive commands aren't captured by cmdr either; capture dropped", n)
+		step.CaptureOutput, step.CaptureEnv = false, false
+	}
+
+	body := s.commandBody(step)
+	onError := ""
+	if step.IgnoreError {
+		onError = ` || echo "⚠️  Command failed but continuing"`
+	}
+
+	switch {
+	case step.CaptureOutput:
+		target := "__cmdr_output"
+		if step.OutputVariable != "" {
+			target = shellVar(step.OutputVariable)
+		}
+		s.writeBlock(target+"=$(", body, ")"+onError)
+
+	case step.CaptureEnv:
+		// cmdr reads KEY=VALUE lines from both streams and exports them.
+		s.writeBlock("__cmdr_output=$( (", body, ") 2>&1 )"+onError)
+		s.line(`printf '%%s\n' "$__cmdr_output"`)
+		s.line(`eval "$(printf '%%s\n' "$__cmdr_output" | __cmdr_exports)"`)
+		s.capturesEnv = true
+
+	case len(body) == 1:
+		s.line("%s%s", body[0], onError)
+
+	default:
+		s.writeBlock("(", body, ")"+onError)
+	}
+}
+
+// writeBlock writes body between open and close, on one line if it's a
+// single line.
+func (s *scriptWriter) writeBlock(open string, body []string, close string) {
+	if len(body) == 1 {
+		s.line("%s %s %s", open, body[0], close)
+		return
+	}
+	s.line("%s", open)
+	s.indent++
+	for _, l := range body {
+		s.line("%s", l)
+	}
+	s.indent--
+	s.line("%s", close)
+}
+
+// commandBody returns the lines that run a command step: changing to its
+// directory, setting its env, then running it in its shell. A body of more
+// than one line must run in a subshell so the changes don't leak.
+func (s *scriptWriter) commandBody(step Step) []string {
+	w := s.wf
+	var lines []string
+
+	for _, dir := range []string{w.Dir, step.Dir} {
+		if dir != "" {
+			lines = append(lines, "cd "+s.dirWord(dir))
+		}
+	}
+	for _, env := range []map[string]string{w.Env, step.Env} {
+		for _, k := range sortedKeys(env) {
+			lines = append(lines, fmt.Sprintf("export %s=%s", k, s.word(env[k])))
+		}
+	}
+	for _, k := range step.UnsetEnv {
+		lines = append(lines, "unset "+k)
+	}
+
+	shell := shellFor(w, step)
+	strict := strictFor(w, step) && shell.posix()
+	code := step.Command
+	if step.Script != "" {
+		code = step.Script
+	}
+
+	inline := step.Script == "" && len(shell) == 1 && (shell.name() == "sh" || shell.name() == "bash")
+	if inline {
+		if strict {
+			lines = append(lines, "set -euo pipefail")
+		}
+		if templateVar.MatchString(code) {
+			// Substitute first and let the shell parse the result, as
+			// cmdr does.
+			lines = append(lines, "eval "+s.word(code))
+		} else {
+			lines = append(lines, strings.Split(strings.TrimRight(code, "\n"), "\n")...)
+		}
+		return lines
+	}
+
+	if strict {
+		code = shell.strictPrelude() + code
+	}
+	// The code is the last argument: inline, or for a script the path of
+	// a file holding it, here a process substitution.
+	var argv []string
+	last := s.word(code)
+	if step.Script != "" {
+		argv = shell.argv("", "-")
+		last = "<(printf '%s' " + last + ")"
+	} else {
+		argv = shell.argv(code, "")
+	}
+	words := make([]string, len(argv))
+	for i, arg := range argv[:len(argv)-1] {
+		words[i] = quotePOSIX(arg)
+	}
+	words[len(argv)-1] = last
+	return append(lines, strings.Join(words, " "))
+}
+
+// word quotes s as a single shell word, with its {{variables}} expanded.
+func (s *scriptWriter) word(text string) string {
+	if !templateVar.MatchString(text) {
+		return quotePOSIX(text)
+	}
+	return `"` + escapeDouble(text) + `"`
+}
+
+// dirWord is word for a directory, which may start with ~.
+func (s *scriptWriter) dirWord(dir string) string {
+	if dir == "~" {
+		return `"$HOME"`
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		return `"$HOME"/` + s.word(rest)
+	}
+	return s.word(dir)
+}
+
+// escapeDouble escapes text for a double-quoted string, turning each
+// {{variable}} into an expansion of its shell variable.
+func escapeDouble(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range templateVar.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(escapeDoubleLiteral(text[last:m[0]]))
+		sb.WriteString("${" + shellName(text[m[2]:m[3]]) + "}")
+		last = m[1]
+	}
+	sb.WriteString(escapeDoubleLiteral(text[last:]))
+	return sb.String()
+}
+
+func escapeDoubleLiteral(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(s)
+}
+
+// shellName is the shell variable a template reads: the env var itself
+// for env.NAME, so values exported by earlier commands show up.
+func shellName(name string) string {
+	if key, ok := strings.CutPrefix(name, "env."); ok {
+		return shellVar(key)
+	}
+	return shellVar(name)
+}
+
+// shellVar maps a cmdr variable name to a shell variable name.
+func shellVar(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
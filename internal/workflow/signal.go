@@ -0,0 +1,108 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultGracePeriod is how long an interrupted command has to exit before
+// it is killed.
+const DefaultGracePeriod = 5 * time.Second
+
+// Interrupted is the error a run returns when it was stopped by a signal.
+type Interrupted struct {
+	Signal os.Signal
+}
+
+func (i *Interrupted) Error() string {
+	return fmt.Sprintf("workflow interrupted (%s)", i.Signal)
+}
+
+// ExitCode follows the shell convention of 128 plus the signal number.
+func (i *Interrupted) ExitCode() int {
+	if sig, ok := i.Signal.(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+	return 130
+}
+
+// NotifyContext returns a context that is cancelled with an *Interrupted
+// cause when the process receives SIGINT or SIGTERM. Only the first signal
+// is caught, so a second one kills cmdr as usual.
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			cancel(&Interrupted{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(context.Canceled)
+	}
+}
+
+// interruptError returns why ctx was cancelled. Cancellations that weren't
+// caused by a signal are reported as SIGTERM.
+func interruptError(ctx context.Context) *Interrupted {
+	cause := context.Cause(ctx)
+	var intr *Interrupted
+	if errors.As(cause, &intr) {
+		return intr
+	}
+	return &Interrupted{Signal: syscall.SIGTERM}
+}
+
+// runProcess runs cmd in its own process group, so stopping it stops
+// everything it started. With foreground set the group has the terminal
+// while it runs, and the terminal's ctrl+c reaches only the command; the
+// run is interrupted if the command dies of it. When ctx is cancelled the
+// group is sent the interrupting signal, then killed if it hasn't exited
+// within the grace period.
+func (e *Executor) runProcess(ctx context.Context, cmd *exec.Cmd, foreground bool) error {
+	restore := setProcessGroup(cmd, foreground)
+	if err := cmd.Start(); err != nil {
+		restore()
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		restore()
+		if foreground && killedBy(cmd, os.Interrupt) && e.interrupt != nil {
+			e.interrupt(os.Interrupt)
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	intr := interruptError(ctx)
+	signalProcessGroup(cmd, intr.Signal)
+
+	timer := time.NewTimer(e.grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		signalProcessGroup(cmd, os.Kill)
+		<-done
+	}
+	restore()
+	return intr
+}
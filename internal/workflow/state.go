@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// State is per-user data that doesn't belong in the shared workflows file:
+// which workflows are pinned as favorites and how often each has been run.
+type State struct {
+	Favorites []string            `yaml:"favorites,omitempty"`
+	History   map[string]RunStats `yaml:"history,omitempty"`
+}
+
+// RunStats records how often and how recently a workflow was run.
+type RunStats struct {
+	Count   int       `yaml:"count"`
+	LastRun time.Time `yaml:"last_run"`
+}
+
+// IsFavorite reports whether a workflow key is pinned.
+func (s *State) IsFavorite(key string) bool {
+	return slices.Contains(s.Favorites, key)
+}
+
+// LoadState reads the user's state file. A missing file is an empty state.
+func (s *Store) LoadState() (*State, error) {
+	state := &State{History: make(map[string]RunStats)}
+
+	data, err := os.ReadFile(s.statePath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.History == nil {
+		state.History = make(map[string]RunStats)
+	}
+	return state, nil
+}
+
+func (s *Store) saveState(state *State) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath, data, 0644)
+}
+
+// ToggleFavorite pins or unpins a workflow and reports whether it is now
+// a favorite.
+func (s *Store) ToggleFavorite(key string) (bool, error) {
+	state, err := s.LoadState()
+	if err != nil {
+		return false, err
+	}
+
+	favorite := !state.IsFavorite(key)
+	if favorite {
+		state.Favorites = append(state.Favorites, key)
+	} else {
+		state.Favorites = slices.DeleteFunc(state.Favorites, func(k string) bool { return k == key })
+	}
+	return favorite, s.saveState(state)
+}
+
+// RecordRun bumps a workflow's run count and last-run time.
+func (s *Store) RecordRun(key string) error {
+	state, err := s.LoadState()
+	if err != nil {
+		return err
+	}
+
+	stats := state.History[key]
+	stats.Count++
+	stats.LastRun = time.Now()
+	state.History[key] = stats
+	return s.saveState(state)
+}
+
+// renameState moves favorites and history from one key to another after a
+// workflow's key changes. An empty newKey forgets the workflow entirely.
+func (s *Store) renameState(oldKey, newKey string) error {
+	state, err := s.LoadState()
+	if err != nil {
+		return err
+	}
+
+	for i, k := range state.Favorites {
+		if k == oldKey {
+			state.Favorites[i] = newKey
+		}
+	}
+	state.Favorites = slices.DeleteFunc(state.Favorites, func(k string) bool { return k == "" })
+
+	if stats, ok := state.History[oldKey]; ok {
+		delete(state.History, oldKey)
+		if newKey != "" {
+			state.History[newKey] = stats
+		}
+	}
+	return s.saveState(state)
+}
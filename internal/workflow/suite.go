@@ -0,0 +1,306 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestFile is a *_test.yaml file: test cases for one workflow.
+type TestFile struct {
+	Path     string     `yaml:"-"`
+	Workflow string     `yaml:"workflow"` // key of the workflow under test
+	Tests    []TestCase `yaml:"tests"`
+}
+
+// TestCase runs a workflow with scripted answers and mocked commands, then
+// checks what happened. Nothing is executed for real: a command without a
+// matching mock fails the test.
+type TestCase struct {
+	Name     string            `yaml:"name"`
+	Params   map[string]string `yaml:"params,omitempty"`   // preset variables, over the params' defaults
+	Answers  map[string]string `yaml:"answers,omitempty"`  // prompt answers by variable
+	Builtins map[string]string `yaml:"builtins,omitempty"` // built-in variables, over testBuiltins
+	Mocks    []Mock            `yaml:"mocks,omitempty"`
+	Expect   Expectations      `yaml:"expect"`
+}
+
+// testBuiltins stand in for the built-in variables in tests, so a test
+// gives the same result on any machine and never runs git. The env.*
+// built-ins are unset unless a test sets them.
+var testBuiltins = map[string]string{
+	"git.branch":    "main",
+	"git.sha":       "0123456789abcdef0123456789abcdef01234567",
+	"git.short_sha": "0123456",
+	"git.root":      "/repo",
+	"git.dirty":     "false",
+	"cwd":           "/repo",
+	"user":          "tester",
+	"hostname":      "localhost",
+	"os":            "linux",
+	"arch":          "amd64",
+	"now":           "2025-01-01T00:00:00Z",
+}
+
+// Mock stands in for the commands matching a regexp.
+type Mock struct {
+	Match    string `yaml:"match"`
+	Stdout   string `yaml:"stdout,omitempty"`
+	Stderr   string `yaml:"stderr,omitempty"`
+	ExitCode int    `yaml:"exit_code,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Expectations are checked after a test's run. Unset fields aren't
+// checked, except that the run must succeed unless Error is set.
+type Expectations struct {
+	Ran      []StepRef         `yaml:"ran,omitempty"`
+	Skipped  []StepRef         `yaml:"skipped,omitempty"`
+	Vars     map[string]string `yaml:"vars,omitempty"`
+	Commands []string          `yaml:"commands,omitempty"` // regexps, one per command in order
+	Error    string            `yaml:"error,omitempty"`    // regexp the run's error must match
+}
+
+// StepRef names a step by its number, counting from 1, or its label.
+type StepRef struct {
+	Number int
+	Label  string
+}
+
+func (r *StepRef) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: a step is a number or a label", value.Line)
+	}
+	if n, err := strconv.Atoi(value.Value); err == nil && value.Tag == "!!int" {
+		r.Number = n
+		return nil
+	}
+	r.Label = value.Value
+	return nil
+}
+
+func (r StepRef) MarshalYAML() (any, error) {
+	if r.Label == "" {
+		return r.Number, nil
+	}
+	return r.Label, nil
+}
+
+func (r StepRef) String() string {
+	if r.Label == "" {
+		return fmt.Sprintf("step %d", r.Number)
+	}
+	return fmt.Sprintf("step %q", r.Label)
+}
+
+func (r StepRef) matches(step int, label string) bool {
+	if r.Label == "" {
+		return r.Number == step
+	}
+	return r.Label == label
+}
+
+// LoadTestFile reads and checks a test file.
+func LoadTestFile(path string) (*TestFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tf TestFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	tf.Path = path
+
+	if tf.Workflow == "" {
+		return nil, fmt.Errorf("%s: no workflow given", path)
+	}
+	for i := range tf.Tests {
+		tc := &tf.Tests[i]
+		if tc.Name == "" {
+			tc.Name = fmt.Sprintf("test %d", i+1)
+		}
+		for j := range tc.Mocks {
+			re, err := regexp.Compile(tc.Mocks[j].Match)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: invalid mock match: %w", path, tc.Name, err)
+			}
+			tc.Mocks[j].re = re
+		}
+		for _, pattern := range append([]string{tc.Expect.Error}, tc.Expect.Commands...) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("%s: %s: invalid pattern: %w", path, tc.Name, err)
+			}
+		}
+	}
+	return &tf, nil
+}
+
+// TestResult is the outcome of one test case.
+type TestResult struct {
+	Name     string
+	Failures []string
+	Duration time.Duration
+	// Output is what the run printed, for showing with failures.
+	Output string
+}
+
+// Passed reports whether every expectation held.
+func (r *TestResult) Passed() bool { return len(r.Failures) == 0 }
+
+func (r *TestResult) failf(format string, args ...any) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+}
+
+// Run runs the test case against wf and checks its expectations.
+func (tc *TestCase) Run(ctx context.Context, wf *Workflow) *TestResult {
+	result := &TestResult{Name: tc.Name}
+	started := time.Now()
+
+	var output bytes.Buffer
+	runner := &mockRunner{mocks: tc.Mocks}
+	builtins := maps.Clone(testBuiltins)
+	maps.Copy(builtins, tc.Builtins)
+	var ran, skipped []Event
+	observer := ObserverFunc(func(event Event) {
+		switch event.Type {
+		case EventStepStarted:
+			ran = append(ran, event)
+		case EventStepSkipped:
+			skipped = append(skipped, event)
+		}
+	})
+
+	executor := NewExecutor(
+		WithStdin(bytes.NewReader(nil)),
+		WithStdout(&output),
+		WithStderr(&output),
+		WithPrompter(NewScriptedPrompter(tc.Answers)),
+		WithRunner(runner),
+		WithObserver(observer),
+		WithBuiltins(builtins),
+	)
+	vars := wf.Defaults()
+	for name, value := range tc.Params {
+		vars[name] = value
+	}
+	executor.SetVariables(vars)
+
+	err := executor.Execute(ctx, wf)
+	result.Duration = time.Since(started)
+	result.Output = output.String()
+
+	switch {
+	case tc.Expect.Error == "" && err != nil:
+		result.failf("run failed: %v", err)
+	case tc.Expect.Error != "" && err == nil:
+		result.failf("run succeeded, want error matching %q", tc.Expect.Error)
+	case tc.Expect.Error != "" && !regexp.MustCompile(tc.Expect.Error).MatchString(err.Error()):
+		result.failf("run failed with %q, want error matching %q", err, tc.Expect.Error)
+	}
+
+	for _, ref := range tc.Expect.Ran {
+		if !containsStep(ran, ref) {
+			result.failf("%s didn't run", ref)
+		}
+	}
+	for _, ref := range tc.Expect.Skipped {
+		if !containsStep(skipped, ref) {
+			result.failf("%s wasn't skipped", ref)
+		}
+	}
+
+	names := make([]string, 0, len(tc.Expect.Vars))
+	for name := range tc.Expect.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := tc.Expect.Vars[name]
+		if got, ok := executor.Variable(name); !ok {
+			result.failf("variable %s is unset, want %q", name, want)
+		} else if got != want {
+			result.failf("variable %s = %q, want %q", name, got, want)
+		}
+	}
+
+	if tc.Expect.Commands != nil {
+		for i, pattern := range tc.Expect.Commands {
+			if i >= len(runner.commands) {
+				result.failf("command %d: missing, want match for %q", i+1, pattern)
+				continue
+			}
+			if !regexp.MustCompile(pattern).MatchString(runner.commands[i]) {
+				result.failf("command %d: %q doesn't match %q", i+1, runner.commands[i], pattern)
+			}
+		}
+		for i := len(tc.Expect.Commands); i < len(runner.commands); i++ {
+			result.failf("command %d: unexpected %q", i+1, runner.commands[i])
+		}
+	}
+
+	return result
+}
+
+func containsStep(events []Event, ref StepRef) bool {
+	for _, event := range events {
+		if ref.matches(event.Step, event.Label) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── Mocked commands ──────────────────────────────────────────────────────────
+
+// mockRunner answers commands from mocks instead of running them, and
+// records each command it was asked to run.
+type mockRunner struct {
+	mocks    []Mock
+	commands []string
+}
+
+func (r *mockRunner) Run(ctx context.Context, p *Process) error {
+	r.commands = append(r.commands, p.Command)
+
+	for _, mock := range r.mocks {
+		if !mock.re.MatchString(p.Command) {
+			continue
+		}
+		writeMockOutput(p.Cmd.Stdout, mock.Stdout)
+		writeMockOutput(p.Cmd.Stderr, mock.Stderr)
+		if mock.ExitCode != 0 {
+			return mockExit(mock.ExitCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("no mock matches command %q", p.Command)
+}
+
+func writeMockOutput(w io.Writer, s string) {
+	if w == nil || s == "" {
+		return
+	}
+	io.WriteString(w, s)
+	if s[len(s)-1] != '\n' {
+		io.WriteString(w, "\n")
+	}
+}
+
+// mockExit is the error for a mocked command's non-zero exit.
+type mockExit int
+
+func (e mockExit) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func (e mockExit) ExitCode() int { return int(e) }
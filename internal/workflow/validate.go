@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kevmul/cmdr/internal/template"
+	"gopkg.in/yaml.v3"
+)
+
+var validOperators = map[string]bool{
+	"equals":     true,
+	"not_equals": true,
+	"empty":      true,
+	"not_empty":  true,
+}
+
+// Validate checks that a workflow is complete enough to run: it has a name
+// and a valid key, and every step has the fields its type needs. All
+// problems are reported together.
+func (w *Workflow) Validate() error {
+	var errs []error
+
+	if w.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if w.Key == "" {
+		errs = append(errs, errors.New("key is required"))
+	} else if Slugify(w.Key) != w.Key {
+		errs = append(errs, fmt.Errorf("key %q must be lowercase letters, numbers and hyphens (try %q)", w.Key, Slugify(w.Key)))
+	}
+
+	errs = append(errs, w.validateParams()...)
+
+	for i, step := range w.allSteps() {
+		if err := step.Validate(); err != nil {
+			errs = append(errs, prefixErrors(fmt.Sprintf("step %d", i+1), err))
+		}
+		if step.Type == StepTypeCommand && strictFor(w, step) && !shellFor(w, step).posix() {
+			errs = append(errs, fmt.Errorf("step %d: strict mode needs a POSIX shell, not %s", i+1, shellFor(w, step)))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(w.Insert)) {
+		for i, step := range w.Insert[name] {
+			if step.Slot != "" {
+				errs = append(errs, fmt.Errorf("insert %q: step %d: inserted steps can't have slots", name, i+1))
+			} else if err := step.Validate(); err != nil {
+				errs = append(errs, prefixErrors(fmt.Sprintf("insert %q: step %d", name, i+1), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks a single step for missing or unknown fields.
+func (s Step) Validate() error {
+	if s.Use != "" || s.Slot != "" {
+		return s.validatePlaceholder()
+	}
+
+	var errs []error
+	if s.With != nil {
+		errs = append(errs, errors.New("with is only for use steps"))
+	}
+
+	switch s.Type {
+	case StepTypeMessage:
+		if s.Prompt == "" {
+			errs = append(errs, errors.New("message steps need a prompt"))
+		}
+	case StepTypeInput, StepTypeConfirm, StepTypeFile:
+		if s.Variable == "" {
+			errs = append(errs, fmt.Errorf("%s steps need a variable", s.Type))
+		}
+	case StepTypeSelect:
+		if s.Variable == "" {
+			errs = append(errs, errors.New("select steps need a variable"))
+		}
+		if len(s.Options) == 0 {
+			errs = append(errs, errors.New("select steps need at least one option"))
+		}
+	case StepTypeCommand:
+		switch {
+		case s.Command == "" && s.Script == "":
+			errs = append(errs, errors.New("command steps need a command or a script"))
+		case s.Command != "" && s.Script != "":
+			errs = append(errs, errors.New("command steps take a command or a script, not both"))
+		}
+	case StepTypeSet:
+		if len(s.Values) == 0 {
+			errs = append(errs, errors.New("set steps need values"))
+		}
+		seen := make(map[string]bool, len(s.Values))
+		for _, a := range s.Values {
+			switch {
+			case a.Name == "":
+				errs = append(errs, errors.New("set values need a variable name"))
+			case seen[a.Name]:
+				errs = append(errs, fmt.Errorf("%s is set twice", a.Name))
+			}
+			seen[a.Name] = true
+			if err := template.Check(a.Value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", a.Name, err))
+			}
+		}
+	case "":
+		errs = append(errs, errors.New("type is required"))
+	default:
+		errs = append(errs, fmt.Errorf("unknown step type %q", s.Type))
+	}
+
+	if s.Condition != nil {
+		if s.Condition.Variable == "" {
+			errs = append(errs, errors.New("condition needs a variable"))
+		}
+		if !validOperators[s.Condition.Operator] {
+			errs = append(errs, fmt.Errorf("unknown condition operator %q", s.Condition.Operator))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validatePlaceholder checks a use or slot step, which stands in for the
+// steps it's replaced with and so can't have fields of its own.
+func (s Step) validatePlaceholder() error {
+	if s.Use != "" && s.Slot != "" {
+		return errors.New("a step can't be both a use and a slot")
+	}
+	rest := s
+	rest.Use, rest.With, rest.Slot = "", nil, ""
+	if !reflect.DeepEqual(rest, Step{}) {
+		if s.Use != "" {
+			return errors.New("use steps take no fields besides with")
+		}
+		return errors.New("slot steps take no other fields")
+	}
+	if s.Slot != "" && s.With != nil {
+		return errors.New("with is only for use steps")
+	}
+	return nil
+}
+
+// ValidateFile checks a workflow file in the given format, either a list
+// of workflows like the store's or a bundle. Besides syntax errors and each
+// workflow's Validate problems, it reports unknown fields and duplicate
+// keys. It returns the workflows it found.
+func ValidateFile(data []byte, f Format) ([]Workflow, error) {
+	b, root, err := decodeFile(data, f)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := unknownFields(root, reflect.TypeOf(b).Elem())
+	if root.Kind == yaml.SequenceNode {
+		errs = unknownFields(root, reflect.TypeOf(b.Workflows))
+	}
+
+	seen := make(map[string]bool, len(b.Workflows))
+	for i, w := range b.Workflows {
+		name := w.Key
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if err := w.Validate(); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				errs = append(errs, fmt.Errorf("workflow %s: %s", name, line))
+			}
+		}
+		if w.Key != "" && seen[w.Key] {
+			errs = append(errs, fmt.Errorf("workflow %s: key is used more than once", name))
+		}
+		seen[w.Key] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(b.Fragments)) {
+		fragment := b.Fragments[name]
+		if err := fragment.Validate(); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				errs = append(errs, fmt.Errorf("fragment %s: %s", name, line))
+			}
+		}
+	}
+	return b.Workflows, errors.Join(errs...)
+}
+
+// ValidateFragments checks a fragment library file in the given format for
+// syntax errors, unknown fields and invalid fragments. It returns how many
+// fragments it found.
+func ValidateFragments(data []byte, f Format) (int, error) {
+	root, err := parseNode(data, f)
+	if err != nil || root == nil {
+		return 0, err
+	}
+	var fragments map[string]Fragment
+	if err := root.Decode(&fragments); err != nil {
+		return 0, err
+	}
+
+	errs := unknownFields(root, reflect.TypeOf(fragments))
+	for _, name := range slices.Sorted(maps.Keys(fragments)) {
+		fragment := fragments[name]
+		if err := fragment.Validate(); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				errs = append(errs, fmt.Errorf("fragment %s: %s", name, line))
+			}
+		}
+	}
+	return len(fragments), errors.Join(errs...)
+}
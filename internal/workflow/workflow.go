@@ -6,8 +6,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // StepType represents the type of step in a workflow
@@ -16,8 +14,17 @@ type StepType string
 // SelectOption represents a key-value select option.
 // If Value is empty, Text is used as both display and value.
 type SelectOption struct {
-	Text  string `yaml:"text"`
-	Value string `yaml:"value"`
+	Text        string `yaml:"text"`
+	Value       string `yaml:"value"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// OptionValue returns the value stored when the option is picked.
+func (o SelectOption) OptionValue() string {
+	if o.Value == "" {
+		return o.Text
+	}
+	return o.Value
 }
 
 const (
@@ -26,6 +33,8 @@ const (
 	StepTypeSelect  StepType = "select"
 	StepTypeConfirm StepType = "confirm"
 	StepTypeCommand StepType = "command"
+	StepTypeFile    StepType = "file"
+	StepTypeSet     StepType = "set"
 )
 
 type Condition struct {
@@ -36,13 +45,17 @@ type Condition struct {
 
 // Step represents a single step in a workflow
 type Step struct {
-	Type           StepType       `yaml:"type"`
+	Type           StepType       `yaml:"type,omitempty"`
 	Prompt         string         `yaml:"prompt,omitempty"`
 	HelpText       string         `yaml:"helpText,omitempty"`
 	Variable       string         `yaml:"variable,omitempty"`
 	Variant        string         `yaml:"variant,omitempty"`
 	Options        []SelectOption `yaml:"options,omitempty"`
+	Default        string         `yaml:"default,omitempty"` // preselected option, matched on value then text
 	Command        string         `yaml:"command,omitempty"`
+	Script         string         `yaml:"script,omitempty"` // multi-line program run from a temp file instead of command
+	Shell          Shell          `yaml:"shell,omitempty"`  // overrides the workflow's shell
+	Strict         *bool          `yaml:"strict,omitempty"` // overrides the workflow's strict mode
 	Description    string         `yaml:"description,omitempty"`
 	Condition      *Condition     `yaml:"condition,omitempty"`
 	CaptureOutput  bool           `yaml:"capture_output,omitempty"`
@@ -50,14 +63,93 @@ type Step struct {
 	CaptureEnv     bool           `yaml:"capture_env,omitempty"`  // parse stdout for KEY=VALUE pairs and store in workflow env
 	IgnoreError    bool           `yaml:"ignore_error,omitempty"` // if true, a non-zero exit code does not stop the workflow
 	Interactive    bool           `yaml:"interactive,omitempty"`
+
+	// Command environment, layered over the workflow's
+	Dir      string            `yaml:"dir,omitempty"`       // working directory; templated, relative to the workflow's dir
+	Env      map[string]string `yaml:"env,omitempty"`       // extra env vars; values are templated
+	UnsetEnv []string          `yaml:"unset_env,omitempty"` // env vars removed before the command runs
+
+	// File picker settings
+	Root         string   `yaml:"root,omitempty"`          // directory the picker starts in and cannot leave; templated
+	Filters      []string `yaml:"filters,omitempty"`       // glob patterns matched against file names, e.g. "*.sql"
+	ShowHidden   bool     `yaml:"show_hidden,omitempty"`   // list dotfiles by default
+	DirOnly      bool     `yaml:"dir_only,omitempty"`      // pick directories instead of files
+	Multiple     bool     `yaml:"multiple,omitempty"`      // allow picking several paths
+	Separator    string   `yaml:"separator,omitempty"`     // joins multiple paths, defaults to a space
+	RelativePath bool     `yaml:"relative_path,omitempty"` // store paths relative to the current directory
+
+	// Computed variables, for set steps
+	Values Assignments `yaml:"values,omitempty"` // variable names and templated values, applied in order
+
+	// Placeholders replaced when the workflow is resolved. A step with
+	// one of these has no other fields.
+	Use  string            `yaml:"use,omitempty"`  // fragment whose steps go here
+	With map[string]string `yaml:"with,omitempty"` // the fragment's params
+	Slot string            `yaml:"slot,omitempty"` // insertion point filled by workflows that extend this one
+}
+
+// Label returns a short human-readable summary of the step, used in
+// progress lists.
+func (s Step) Label() string {
+	switch {
+	case s.Description != "":
+		return s.Description
+	case s.Prompt != "":
+		return s.Prompt
+	case s.Command != "":
+		return s.Command
+	case s.Script != "":
+		return strings.TrimSpace(strings.SplitN(strings.TrimSpace(s.Script), "\n", 2)[0])
+	case len(s.Values) > 0:
+		return "set " + strings.Join(s.Values.names(), ", ")
+	case s.Use != "":
+		return "use " + s.Use
+	case s.Slot != "":
+		return "slot " + s.Slot
+	default:
+		return string(s.Type)
+	}
 }
 
 // Workflow represents a complete workflow with multiple steps
 type Workflow struct {
-	Key         string `yaml:"key"`
-	Name        string `yaml:"name"`
-	Description string `yaml:"description,omitempty"`
-	Steps       []Step `yaml:"steps"`
+	Key         string   `yaml:"key"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Group       string   `yaml:"group,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Params      []Param  `yaml:"params,omitempty"`
+	Cd          string   `yaml:"cd,omitempty"` // directory the calling shell moves to after an exported run; templated
+	Steps       []Step   `yaml:"steps,omitempty"`
+	Finally     []Step   `yaml:"finally,omitempty"` // run after the steps even if they fail or are interrupted
+
+	// Defaults for every command step
+	Dir    string            `yaml:"dir,omitempty"`    // working directory; templated, relative to the current directory
+	Env    map[string]string `yaml:"env,omitempty"`    // extra env vars; values are templated
+	Shell  Shell             `yaml:"shell,omitempty"`  // sh unless set
+	Strict bool              `yaml:"strict,omitempty"` // run POSIX shells with set -euo pipefail
+
+	// Inheritance, flattened by Store.Resolve before the workflow runs
+	Extends string            `yaml:"extends,omitempty"` // key of the workflow this one is based on
+	Vars    map[string]string `yaml:"vars,omitempty"`    // variables set before the first step; overridden by params
+	Insert  map[string][]Step `yaml:"insert,omitempty"`  // steps for the base workflow's slots, by slot name
+}
+
+// allSteps returns the steps followed by the finally steps, in the order
+// they are numbered while running.
+func (w *Workflow) allSteps() []Step {
+	return append(append([]Step(nil), w.Steps...), w.Finally...)
+}
+
+// Options returns the options of the select step that sets variable, for
+// completing its values on the command line.
+func (w *Workflow) Options(variable string) []SelectOption {
+	for _, step := range w.Steps {
+		if step.Type == StepTypeSelect && step.Variable == variable {
+			return step.Options
+		}
+	}
+	return nil
 }
 
 var (
@@ -76,7 +168,10 @@ func Slugify(s string) string {
 
 // Store handles loading and saving workflows
 type Store struct {
-	filePath string
+	filePath      string
+	format        Format
+	fragmentsPath string
+	statePath     string
 }
 
 // NewStore creates a new workflow store
@@ -91,7 +186,33 @@ func NewStore() (*Store, error) {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	return &Store{filePath: filepath.Join(configDir, "workflows.yaml")}, nil
+	filePath := configFile(configDir, "workflows")
+	return &Store{
+		filePath:      filePath,
+		format:        FormatOf(filePath),
+		fragmentsPath: configFile(configDir, "fragments"),
+		statePath:     filepath.Join(configDir, "state.yaml"),
+	}, nil
+}
+
+// configFile returns the path of a config file, name.yaml unless there's
+// only a name.json, for those who'd rather write JSON.
+func configFile(dir, name string) string {
+	path := filepath.Join(dir, name+".yaml")
+	if jsonPath := filepath.Join(dir, name+".json"); !fileExists(path) && fileExists(jsonPath) {
+		return jsonPath
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Path returns the file the workflows are stored in.
+func (s *Store) Path() string {
+	return s.filePath
 }
 
 // readAll reads all workflows from the file
@@ -105,15 +226,15 @@ func (s *Store) readAll() ([]Workflow, error) {
 	}
 
 	var workflows []Workflow
-	if err := yaml.Unmarshal(data, &workflows); err != nil {
-		return nil, err
+	if err := Unmarshal(data, s.format, &workflows); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.filePath, err)
 	}
 	return workflows, nil
 }
 
 // writeAll writes all workflows to the file
 func (s *Store) writeAll(workflows []Workflow) error {
-	data, err := yaml.Marshal(workflows)
+	data, err := Marshal(workflows, s.format)
 	if err != nil {
 		return err
 	}
@@ -163,6 +284,37 @@ func (s *Store) Save(workflow *Workflow) error {
 	return s.writeAll(workflows)
 }
 
+// Update replaces the workflow stored under key, keeping its position in
+// the file. The workflow may carry a new key, as long as it isn't taken.
+func (s *Store) Update(key string, workflow *Workflow) error {
+	workflows, err := s.readAll()
+	if err != nil {
+		return err
+	}
+
+	if workflow.Key != key {
+		for _, w := range workflows {
+			if w.Key == workflow.Key {
+				return fmt.Errorf("workflow key already in use: %s", workflow.Key)
+			}
+		}
+	}
+
+	for i, w := range workflows {
+		if w.Key == key {
+			workflows[i] = *workflow
+			if err := s.writeAll(workflows); err != nil {
+				return err
+			}
+			if workflow.Key != key {
+				return s.renameState(key, workflow.Key)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("workflow not found: %s", key)
+}
+
 // Delete deletes a workflow by key
 func (s *Store) Delete(key string) error {
 	workflows, err := s.readAll()
@@ -173,7 +325,10 @@ func (s *Store) Delete(key string) error {
 	for i, w := range workflows {
 		if w.Key == key {
 			workflows = append(workflows[:i], workflows[i+1:]...)
-			return s.writeAll(workflows)
+			if err := s.writeAll(workflows); err != nil {
+				return err
+			}
+			return s.renameState(key, "")
 		}
 	}
 	return fmt.Errorf("workflow not found: %s", key)